@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/config"
+	"github.com/Furkanturan8/goftr-template/internal/dto"
+	"github.com/Furkanturan8/goftr-template/internal/repository"
+	"github.com/Furkanturan8/goftr-template/internal/service"
+	"github.com/Furkanturan8/goftr-template/pkg/cache"
+	"github.com/Furkanturan8/goftr-template/pkg/email"
+	"github.com/Furkanturan8/goftr-template/pkg/jwt"
+	"github.com/Furkanturan8/goftr-template/pkg/password"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/driver/pgdriver"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+=======KULLANIM=======
+# Dosyayı kontrol et, veritabanına yazma (rapor JSON olarak yazdırılır)
+go run cmd/import/main.go -file users.csv -dry-run
+
+# Kullanıcıları 50'lik batch'ler halinde oluştur ve davet e-postası gönder
+go run cmd/import/main.go -file users.json -batch 50 -invite
+*/
+
+func main() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Config yükleme hatası: %v", err)
+		os.Exit(1)
+	}
+
+	var (
+		file   = flag.String("file", "", "CSV veya JSON dosya yolu")
+		format = flag.String("format", "", "Dosya formatı (csv/json), boşsa uzantıdan belirlenir")
+		dryRun = flag.Bool("dry-run", false, "Sadece kontrol et, veritabanına yazma")
+		batch  = flag.Int("batch", 100, "Batch başına kullanıcı sayısı")
+		invite = flag.Bool("invite", false, "Oluşturulan kullanıcılara davet e-postası gönder")
+	)
+	flag.Parse()
+
+	if *file == "" {
+		log.Fatal("-file parametresi gerekli")
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("Dosya açılamadı: %v", err)
+	}
+	defer f.Close()
+
+	rows, err := dto.ParseUserImport(*format, f)
+	if err != nil {
+		log.Fatalf("Dosya okunamadı: %v", err)
+	}
+
+	// Sunucunun liste cache'inin temizlenebilmesi için; cache'e ulaşılamazsa import cache'siz çalışır
+	if cfg.CacheConfig.Fallback == "" {
+		cfg.CacheConfig.Fallback = cache.DriverNone
+	}
+	store, err := cache.New(&cfg.CacheConfig, &cfg.RedisConfig)
+	if err != nil {
+		log.Printf("Cache başlatma hatası: %v", err)
+		store = cache.NewNoopCache()
+	}
+	defer store.Close()
+	cache.SetDefault(store)
+	jwt.Init(&cfg.JWTConfig)
+	if err = password.Init(&cfg.PasswordConfig); err != nil {
+		log.Fatalf("Şifre hash yapılandırma hatası: %v", err)
+	}
+
+	// Veritabanı bağlantısı
+	sqldb := sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(cfg.DBConfig.GetDSN())))
+	db := bun.NewDB(sqldb, pgdialect.New())
+	defer func(db *bun.DB) {
+		err = db.Close()
+		if err != nil {
+			fmt.Printf("Veritabanı kapatma hatası: %v\n", err)
+		}
+	}(db)
+
+	emailPkg := email.NewEmail(
+		cfg.MailConfig.FromEmail,
+		cfg.MailConfig.SMTPPassword,
+		cfg.MailConfig.SMTPHost,
+		cfg.MailConfig.SMTPPort,
+	)
+	importService := service.NewUserImportService(repository.NewTxManager(db), repository.NewUserRepository(db, store), emailPkg, cfg.AppConfig.FrontendURL)
+
+	opts := service.ImportOptions{DryRun: *dryRun, BatchSize: *batch, SendInvites: *invite}
+	candidates := make([]service.ImportCandidate, len(rows))
+	for i, row := range rows {
+		user, errs := row.Prepare(opts.SendInvites)
+		candidates[i] = service.ImportCandidate{Line: row.Line, User: user, Errors: errs}
+	}
+
+	report, err := importService.Import(context.Background(), candidates, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+	fmt.Printf("Toplam: %d, geçerli: %d, oluşturulan: %d, hatalı: %d, tekrar: %d, başarısız: %d\n",
+		report.Total, report.Valid, report.Created, report.Invalid, report.Duplicates, report.Failed)
+
+	if report.Invalid > 0 || report.Duplicates > 0 || report.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -22,8 +22,11 @@ go run cmd/migrate/main.go -action up
 # Son 2 migration'ı geri al
 go run cmd/migrate/main.go -action down -step 2
 
-# Migration listesini görüntüle
+# Uygulanmış ve bekleyen migration'ları görüntüle
 go run cmd/migrate/main.go -action status
+
+# Takip tablosundan önce kurulmuş veritabanını 000011'e kadar uygulanmış olarak işaretle
+go run cmd/migrate/main.go -action baseline -version 000011
 */
 
 func main() {
@@ -35,8 +38,9 @@ func main() {
 
 	// varsayılan olarak "up" işlemi yapılıyor => go run cmd/migrate/main.go
 	var (
-		action = flag.String("action", "up", "Migration action (up/down/status)")
-		step   = flag.Int("step", 0, "Number of migrations (0 for all)")
+		action  = flag.String("action", "up", "Migration action (up/down/status/baseline)")
+		step    = flag.Int("step", 0, "Number of migrations (0 for all)")
+		version = flag.String("version", "", "Baseline version (baseline için)")
 	)
 	flag.Parse()
 
@@ -73,7 +77,12 @@ func main() {
 		}
 		fmt.Println(status)
 
+	case "baseline":
+		if err = migrations.Baseline(ctx, db, *version); err != nil {
+			log.Fatal(err)
+		}
+
 	default:
-		log.Fatal("Geçersiz işlem. 'up', 'down' veya 'status' kullanın")
+		log.Fatal("Geçersiz işlem. 'up', 'down', 'status' veya 'baseline' kullanın")
 	}
 }
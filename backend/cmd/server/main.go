@@ -5,10 +5,16 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/Furkanturan8/goftr-template/config"
+	"github.com/Furkanturan8/goftr-template/internal/job"
+	"github.com/Furkanturan8/goftr-template/internal/repository"
 	"github.com/Furkanturan8/goftr-template/internal/router"
+	"github.com/Furkanturan8/goftr-template/internal/service"
 	"github.com/Furkanturan8/goftr-template/pkg/cache"
+	"github.com/Furkanturan8/goftr-template/pkg/email"
 	"github.com/Furkanturan8/goftr-template/pkg/jwt"
 	"github.com/Furkanturan8/goftr-template/pkg/logger"
+	"github.com/Furkanturan8/goftr-template/pkg/password"
+	"github.com/Furkanturan8/goftr-template/pkg/sms"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
 	"github.com/uptrace/bun/driver/pgdriver"
@@ -33,15 +39,24 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Redis cache'i başlat
-	if err = cache.InitDefaultCache(cfg.RedisConfig.GetAddr(), cfg.RedisConfig.Password, cfg.RedisConfig.DB); err != nil {
-		logger.Error("Redis cache başlatma hatası: %v", err)
+	// Cache backend'ini başlat. Redis'e ulaşılamazsa CACHE_FALLBACK verilmedikçe
+	// bellek içi cache'e geçilmez (token iptali, OTP, kilitler instance'lar arasında paylaşılmalı)
+	store, err := cache.New(&cfg.CacheConfig, &cfg.RedisConfig)
+	if err != nil {
+		logger.Error("Cache başlatma hatası: %v", err)
 		os.Exit(1)
 	}
+	cache.SetDefault(store)
 
 	// JWT yapılandırmasını başlat
 	jwt.Init(&cfg.JWTConfig)
 
+	// Şifre hash algoritmasını ayarla
+	if err = password.Init(&cfg.PasswordConfig); err != nil {
+		logger.Error("Şifre hash yapılandırma hatası: %v", err)
+		os.Exit(1)
+	}
+
 	// Database bağlantısı
 	sqldb := sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(cfg.DBConfig.GetDSN())))
 	db := bun.NewDB(sqldb, pgdialect.New())
@@ -54,8 +69,23 @@ func main() {
 	}
 	logger.Info("Veritabanı bağlantısı başarılı")
 
-	r := router.NewRouter(db, cfg)
-	r.SetupRoutes()
+	// SMS sağlayıcısı (OTP kodları)
+	smsSender, err := sms.New(&cfg.SMSConfig)
+	if err != nil {
+		logger.Error("SMS sağlayıcısı başlatma hatası: %v", err)
+		os.Exit(1)
+	}
+
+	r := router.NewRouter(db, cfg, smsSender, store)
+	if err = r.SetupRoutes(); err != nil {
+		logger.Error("Route yapılandırma hatası: %v", err)
+		os.Exit(1)
+	}
+
+	// Arka plan job'larını başlat
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	jobs := setupJobs(db, cfg, store)
+	jobs.Start(jobCtx)
 
 	// Graceful shutdown için kanal oluştur
 	shutdown := make(chan os.Signal, 1)
@@ -85,10 +115,60 @@ func main() {
 		logger.Error("Sunucu kapatma hatası: %v", err)
 	}
 
-	// Veritabanı bağlantısını kapat
+	// Job'ları durdur
+	stopJobs()
+	jobs.Wait()
+
+	// Veritabanı ve cache bağlantılarını kapat
 	if err = db.Close(); err != nil {
 		logger.Error("Veritabanı bağlantısı kapatma hatası: %v", err)
 	}
+	if err = store.Close(); err != nil {
+		logger.Error("Cache bağlantısı kapatma hatası: %v", err)
+	}
 
 	logger.Info("Sunucu başarıyla kapatıldı")
 }
+
+// Süresi dolan ban ve askıların kontrol aralığı
+const userStatusExpiryInterval = 5 * time.Minute
+
+// Periyodik arka plan job'larını kaydeder
+func setupJobs(db *bun.DB, cfg *config.Config, store cache.Store) *job.Runner {
+	runner := job.NewRunner()
+	// Veritabanı üzerinde çalışan job'lar birden fazla instance'ta aynı anda çalışmasın
+	if locker, ok := store.(cache.Locker); ok {
+		runner.WithLocker(locker)
+	}
+
+	userRepo := repository.NewUserRepository(db, store)
+	txManager := repository.NewTxManager(db)
+	authRepo := repository.NewAuthRepository(db)
+	userService := service.NewUserService(txManager, userRepo, authRepo)
+
+	if cfg.RetentionConfig.Enabled {
+		interval := time.Duration(cfg.RetentionConfig.IntervalHours) * time.Hour
+		runner.EveryExclusive("user-retention", interval, job.UserRetention(userService, cfg.RetentionConfig))
+	}
+
+	// Hesap silme talepleri saklama politikasından bağımsız olarak her zaman işlenir
+	deletionDelay := time.Duration(cfg.RetentionConfig.AccountDeletionDays) * 24 * time.Hour
+	accountService := service.NewAccountService(txManager, userRepo, authRepo, deletionDelay)
+	runner.EveryExclusive("account-deletion", time.Hour, job.AccountDeletion(accountService))
+
+	emailPkg := email.NewEmail(
+		cfg.MailConfig.FromEmail,
+		cfg.MailConfig.SMTPPassword,
+		cfg.MailConfig.SMTPHost,
+		cfg.MailConfig.SMTPPort,
+	)
+	statusService := service.NewUserStatusService(txManager, userRepo, repository.NewUserStatusRepository(db), emailPkg)
+	runner.EveryExclusive("user-status-expiry", userStatusExpiryInterval, job.UserStatusExpiry(statusService))
+
+	exportTTL := time.Duration(cfg.ExportConfig.TTLHours) * time.Hour
+	exportService := service.NewUserExportService(userRepo, store, cfg.ExportConfig.Dir, cfg.ExportConfig.AsyncThreshold, exportTTL)
+	// Export dosyaları instance'ın diskinde tutulduğu için her instance kendi dosyalarını temizler
+	runner.Every("user-export-cleanup", time.Hour, job.UserExportCleanup(exportService))
+
+	return runner
+}
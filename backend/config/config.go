@@ -5,15 +5,22 @@ import (
 	"github.com/joho/godotenv"
 	"os"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
 	AppConfig        AppConfig
 	DBConfig         DBConfig
 	RedisConfig      RedisConfig
+	CacheConfig      CacheConfig
+	RateLimitConfig  RateLimitConfig
 	JWTConfig        JWTConfig
 	MonitoringConfig MonitoringConfig
 	MailConfig       MailConfig
+	RetentionConfig  RetentionConfig
+	ExportConfig     ExportConfig
+	SMSConfig        SMSConfig
+	PasswordConfig   PasswordConfig
 }
 
 type AppConfig struct {
@@ -23,6 +30,7 @@ type AppConfig struct {
 	Env             string
 	ShutdownTimeout int
 	LogDir          string
+	FrontendURL     string // E-postalardaki linkler için (şifre sıfırlama, davet)
 }
 
 type DBConfig struct {
@@ -42,7 +50,51 @@ type RedisConfig struct {
 	PoolSize      int
 	MinIdleConns  int
 	MaxRetries    int
-	RetryInterval int
+	RetryInterval int // Milisaniye; tekrar denemeler arasındaki en kısa bekleme
+
+	// Topoloji: standalone, sentinel veya cluster
+	Mode string
+	// Sentinel veya cluster düğümleri; boşsa Host:Port kullanılır
+	Addrs      []string
+	MasterName string // Sentinel'in yönettiği master adı
+	// Redis 6+ ACL kullanıcısı
+	Username         string
+	SentinelUsername string
+	SentinelPassword string
+
+	TLS RedisTLSConfig
+}
+
+type RedisTLSConfig struct {
+	Enabled            bool
+	CAFile             string // Boşsa sistem sertifikaları kullanılır
+	CertFile           string // Client sertifikası (mTLS)
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// Cache backend'i. Driver: redis, memory (tek instance, LRU + TTL) veya none
+type CacheConfig struct {
+	Driver           string
+	MemoryMaxEntries int
+	// Açılışta Redis'e ulaşılamazsa kullanılacak backend (memory veya none); boşsa Redis beklenir
+	Fallback string
+
+	// Redis önünde process içi L1 cache; invalidation'lar Redis pub/sub ile yayılır
+	L1Enabled    bool
+	L1MaxEntries int
+	L1TTLSeconds int
+}
+
+// İstek sınırları. Politikalar "plan=istek/süre" listesidir (ör. "*=120/1m,user=300/1m");
+// plan API key'in planı, giriş yapmış kullanıcının rolü veya "anonymous" olur, "*" diğer tüm planlar içindir.
+type RateLimitConfig struct {
+	Enabled bool
+	API     string // Tüm /api/v1 istekleri
+	Auth    string // /api/v1/auth istekleri (giriş, kayıt, SMS kodu); API limitine ek olarak uygulanır
+	// X-API-Key ile gelen istemcilerin planları: "key=plan" listesi. Sadece limit için kullanılır, yetki vermez.
+	APIKeys string
 }
 
 type JWTConfig struct {
@@ -81,6 +133,55 @@ type MailConfig struct {
 	FromEmail    string
 }
 
+// Soft delete ile silinmiş kayıtların saklanma politikası
+type RetentionConfig struct {
+	Enabled       bool
+	UserDays      int    // Silinmiş kullanıcıların saklanacağı gün sayısı
+	UserMode      string // purge (kalıcı sil) veya anonymize (kişisel veriyi temizle)
+	IntervalHours int    // Temizlik job'unun çalışma aralığı
+
+	AccountDeletionDays int // Kullanıcının hesap silme talebinden sonra anonimleştirmeye kadar bekleme süresi
+}
+
+// Dışa aktarma (export) dosyaları
+type ExportConfig struct {
+	Dir            string // Arka planda oluşturulan dosyaların dizini
+	AsyncThreshold int    // Bu sayıdan fazla kayıt varsa export arka planda çalışır
+	TTLHours       int    // Dosyaların indirilebilir kalacağı süre
+}
+
+// SMS gönderimi (OTP kodları). Provider: log (geliştirme) veya http
+type SMSConfig struct {
+	Provider    string
+	From        string
+	LogFile     string // log provider için; boşsa uygulama loguna yazılır
+	HTTPURL     string
+	HTTPToken   string
+	HTTPTimeout int // Saniye
+}
+
+// Şifre hash'leme ve şifre politikası. Algorithm: argon2id, bcrypt veya pbkdf2-sha256.
+// Parametreleri değişen hash'ler kullanıcı giriş yaptığında yenilenir.
+type PasswordConfig struct {
+	Algorithm         string
+	Argon2Memory      int // KiB
+	Argon2Iterations  int
+	Argon2Parallelism int
+	BcryptCost        int
+	PBKDF2Iterations  int
+
+	MinLength          int
+	MaxLength          int
+	RequireUpper       bool
+	RequireLower       bool
+	RequireDigit       bool
+	RequireSymbol      bool
+	ForbidPersonalInfo bool   // E-posta, ad, soyad ve kullanıcı adı şifrede geçemez
+	HistorySize        int    // Son kaç şifre tekrar kullanılamaz; 0 ise kontrol yapılmaz
+	MaxAgeDays         int    // 0 ise şifrenin süresi dolmaz
+	BreachedDir        string // k-anonimlik formatında sızdırılmış şifre veri seti; boşsa kontrol yapılmaz
+}
+
 func LoadConfig() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		return nil, err
@@ -94,6 +195,7 @@ func LoadConfig() (*Config, error) {
 			Version:         getEnv("APP_VERSION", "1.0.0"),
 			ShutdownTimeout: getEnvAsInt("APP_SHUTDOWN_TIMEOUT", 5),
 			LogDir:          getEnv("APP_LOG_DIR", "./logs"),
+			FrontendURL:     getEnv("APP_FRONTEND_URL", "http://localhost:5173"),
 		},
 		DBConfig: DBConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -112,6 +214,37 @@ func LoadConfig() (*Config, error) {
 			MinIdleConns:  getEnvAsInt("REDIS_MIN_IDLE_CONNS", 5),
 			MaxRetries:    getEnvAsInt("REDIS_MAX_RETRIES", 3),
 			RetryInterval: getEnvAsInt("REDIS_RETRY_INTERVAL", 100),
+
+			Mode:             getEnv("REDIS_MODE", "standalone"),
+			Addrs:            getEnvAsSlice("REDIS_ADDRS", nil),
+			MasterName:       getEnv("REDIS_MASTER_NAME", ""),
+			Username:         getEnv("REDIS_USERNAME", ""),
+			SentinelUsername: getEnv("REDIS_SENTINEL_USERNAME", ""),
+			SentinelPassword: getEnv("REDIS_SENTINEL_PASSWORD", ""),
+
+			TLS: RedisTLSConfig{
+				Enabled:            getEnvAsBool("REDIS_TLS_ENABLED", false),
+				CAFile:             getEnv("REDIS_TLS_CA_FILE", ""),
+				CertFile:           getEnv("REDIS_TLS_CERT_FILE", ""),
+				KeyFile:            getEnv("REDIS_TLS_KEY_FILE", ""),
+				ServerName:         getEnv("REDIS_TLS_SERVER_NAME", ""),
+				InsecureSkipVerify: getEnvAsBool("REDIS_TLS_INSECURE_SKIP_VERIFY", false),
+			},
+		},
+		CacheConfig: CacheConfig{
+			Driver:           getEnv("CACHE_DRIVER", "redis"),
+			MemoryMaxEntries: getEnvAsInt("CACHE_MEMORY_MAX_ENTRIES", 10000),
+			Fallback:         getEnv("CACHE_FALLBACK", ""),
+
+			L1Enabled:    getEnvAsBool("CACHE_L1_ENABLED", false),
+			L1MaxEntries: getEnvAsInt("CACHE_L1_MAX_ENTRIES", 10000),
+			L1TTLSeconds: getEnvAsInt("CACHE_L1_TTL_SECONDS", 30),
+		},
+		RateLimitConfig: RateLimitConfig{
+			Enabled: getEnvAsBool("RATE_LIMIT_ENABLED", true),
+			API:     getEnv("RATE_LIMIT_API", "*=120/1m,user=300/1m,admin=1000/1m"),
+			Auth:    getEnv("RATE_LIMIT_AUTH", "*=20/1m"),
+			APIKeys: getEnv("RATE_LIMIT_API_KEYS", ""),
 		},
 		JWTConfig: JWTConfig{
 			Secret:            getEnv("JWT_SECRET", ""),
@@ -142,6 +275,46 @@ func LoadConfig() (*Config, error) {
 			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
 			FromEmail:    getEnv("SMTP_FROM_EMAIL", ""),
 		},
+		RetentionConfig: RetentionConfig{
+			Enabled:       getEnvAsBool("RETENTION_ENABLED", true),
+			UserDays:      getEnvAsInt("RETENTION_USER_DAYS", 30),
+			UserMode:      getEnv("RETENTION_USER_MODE", "purge"),
+			IntervalHours: getEnvAsInt("RETENTION_INTERVAL_HOURS", 24),
+
+			AccountDeletionDays: getEnvAsInt("RETENTION_ACCOUNT_DELETION_DAYS", 14),
+		},
+		ExportConfig: ExportConfig{
+			Dir:            getEnv("EXPORT_DIR", "./exports"),
+			AsyncThreshold: getEnvAsInt("EXPORT_ASYNC_THRESHOLD", 5000),
+			TTLHours:       getEnvAsInt("EXPORT_TTL_HOURS", 24),
+		},
+		SMSConfig: SMSConfig{
+			Provider:    getEnv("SMS_PROVIDER", "log"),
+			From:        getEnv("SMS_FROM", ""),
+			LogFile:     getEnv("SMS_LOG_FILE", ""),
+			HTTPURL:     getEnv("SMS_HTTP_URL", ""),
+			HTTPToken:   getEnv("SMS_HTTP_TOKEN", ""),
+			HTTPTimeout: getEnvAsInt("SMS_HTTP_TIMEOUT", 10),
+		},
+		PasswordConfig: PasswordConfig{
+			Algorithm:         getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+			Argon2Memory:      getEnvAsInt("PASSWORD_ARGON2_MEMORY", 64*1024),
+			Argon2Iterations:  getEnvAsInt("PASSWORD_ARGON2_ITERATIONS", 3),
+			Argon2Parallelism: getEnvAsInt("PASSWORD_ARGON2_PARALLELISM", 2),
+			BcryptCost:        getEnvAsInt("PASSWORD_BCRYPT_COST", 10),
+			PBKDF2Iterations:  getEnvAsInt("PASSWORD_PBKDF2_ITERATIONS", 600000),
+
+			MinLength:          getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+			MaxLength:          getEnvAsInt("PASSWORD_MAX_LENGTH", 128),
+			RequireUpper:       getEnvAsBool("PASSWORD_REQUIRE_UPPER", true),
+			RequireLower:       getEnvAsBool("PASSWORD_REQUIRE_LOWER", true),
+			RequireDigit:       getEnvAsBool("PASSWORD_REQUIRE_DIGIT", true),
+			RequireSymbol:      getEnvAsBool("PASSWORD_REQUIRE_SYMBOL", false),
+			ForbidPersonalInfo: getEnvAsBool("PASSWORD_FORBID_PERSONAL_INFO", true),
+			HistorySize:        getEnvAsInt("PASSWORD_HISTORY_SIZE", 5),
+			MaxAgeDays:         getEnvAsInt("PASSWORD_MAX_AGE_DAYS", 0),
+			BreachedDir:        getEnv("PASSWORD_BREACHED_DIR", ""),
+		},
 	}
 
 	return config, nil
@@ -170,6 +343,21 @@ func getEnvAsBool(key string, defaultVal bool) bool {
 	return defaultVal
 }
 
+// Virgülle ayrılmış değerleri boşlukları temizleyerek döner
+func getEnvAsSlice(key string, defaultVal []string) []string {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultVal
+	}
+	var values []string
+	for _, v := range strings.Split(valueStr, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 func (c *DBConfig) GetDSN() string {
 	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
 		c.User,
@@ -184,3 +372,11 @@ func (c *DBConfig) GetDSN() string {
 func (c *RedisConfig) GetAddr() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
+
+// GetAddrs REDIS_ADDRS verilmişse onu, yoksa Host:Port'u döner
+func (c *RedisConfig) GetAddrs() []string {
+	if len(c.Addrs) > 0 {
+		return c.Addrs
+	}
+	return []string{c.GetAddr()}
+}
@@ -1,9 +1,10 @@
 package dto
 
-// Giriş isteği
+// Giriş isteği; e-posta veya kullanıcı adından biri verilmeli
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
+	Email    string `json:"email" validate:"required_without=Username,omitempty,email"`
+	Username string `json:"username" validate:"required_without=Email,omitempty,max=30"`
+	Password string `json:"password" validate:"required"`
 }
 
 // Token yanıtı
@@ -26,9 +27,33 @@ type ForgotPasswordRequest struct {
 // Şifre sıfırlama
 type ResetPasswordRequest struct {
 	Token       string `json:"token" validate:"required"`
-	NewPassword string `json:"new_password" validate:"required,min=6"`
+	NewPassword string `json:"new_password" validate:"required"` // Şifre politikası serviste uygulanır
 }
 
 type RegisterResponse struct {
-	Email string `json:"email"`
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// İki adımlı doğrulama açıksa girişte token yerine dönen yanıt
+type TwoFactorChallengeResponse struct {
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresIn      int    `json:"expires_in"` // Saniye cinsinden
+}
+
+// İki adımlı doğrulamada SMS kodu ile girişi tamamlama
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required,numeric,len=6"`
+}
+
+// Telefona giriş kodu isteği
+type PhoneCodeRequest struct {
+	Phone string `json:"phone" validate:"required,e164"`
+}
+
+// SMS kodu ile giriş
+type PhoneLoginRequest struct {
+	Phone string `json:"phone" validate:"required,e164"`
+	Code  string `json:"code" validate:"required,numeric,len=6"`
 }
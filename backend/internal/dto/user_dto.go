@@ -2,19 +2,25 @@ package dto
 
 import (
 	"github.com/Furkanturan8/goftr-template/internal/model"
+	"github.com/Furkanturan8/goftr-template/pkg/password"
+	"time"
 )
 
 type CreateUserRequest struct {
 	Email     string       `json:"email" validate:"required_without=Phone,omitempty,max=64,email"`
+	Phone     string       `json:"phone" validate:"omitempty,e164"`
+	Username  string       `json:"username" validate:"omitempty,max=30"`
 	FirstName string       `json:"first_name" validate:"required,max=100"`
 	LastName  string       `json:"last_name" validate:"required,max=100"`
-	Password  string       `json:"password" validate:"required,min=3,max=100"`
+	Password  string       `json:"password" validate:"required"` // Şifre politikası PasswordViolations ile uygulanır
 	Status    model.Status `json:"status" validate:"omitempty,oneof=active inactive"`
 	Role      model.Role   `json:"role"`
 }
 
 func (dto CreateUserRequest) ToDBModel(m model.User) model.User {
 	m.Email = dto.Email
+	m.Phone = dto.Phone
+	m.Username = dto.Username
 	m.FirstName = dto.FirstName
 	m.LastName = dto.LastName
 	if dto.Password != "" {
@@ -34,28 +40,46 @@ func (dto CreateUserRequest) ToDBModel(m model.User) model.User {
 	return m
 }
 
+// PasswordViolations şifrenin politikaya göre ihlallerini döner.
+// Yeni kullanıcının şifre geçmişi olmadığı için sadece politika kuralları uygulanır.
+func (dto CreateUserRequest) PasswordViolations() []password.Violation {
+	return password.CheckPolicy(dto.Password, dto.Email, dto.FirstName, dto.LastName, dto.Username)
+}
+
 type UpdateUserRequest struct {
 	Email           string       `json:"email" validate:"omitempty,max=64,email"`
+	Phone           string       `json:"phone" validate:"omitempty,e164"`
+	Username        string       `json:"username" validate:"omitempty,max=30"`
 	FirstName       string       `json:"first_name" validate:"omitempty,max=100"`
 	LastName        string       `json:"last_name" validate:"omitempty,max=100"`
-	CurrentPassword string       `json:"current_password" validate:"omitempty,min=3,max=100"`
-	NewPassword     string       `json:"new_password" validate:"omitempty,min=3,max=100"`
-	Status          model.Status `json:"status" validate:"omitempty,oneof=active inactive"`
+	CurrentPassword string       `json:"current_password"`
+	NewPassword     string       `json:"new_password"`
+	Status          model.Status `json:"status" validate:"omitempty,oneof=active inactive suspended banned"`
 	Role            model.Role   `json:"role"`
 }
 
+// ToDBModel gönderilmeyen (boş) alanlarda mevcut değerleri korur.
+// m, güncellenecek kullanıcının veritabanındaki hali olmalı.
 func (dto UpdateUserRequest) ToDBModel(m model.User) model.User {
-	m.Email = dto.Email
-	m.FirstName = dto.FirstName
-	m.LastName = dto.LastName
-	if dto.Role == "" {
-		m.Role = model.UserRole
-	} else {
+	if dto.Email != "" {
+		m.Email = dto.Email
+	}
+	if dto.Phone != "" {
+		m.Phone = dto.Phone
+	}
+	if dto.Username != "" {
+		m.Username = dto.Username
+	}
+	if dto.FirstName != "" {
+		m.FirstName = dto.FirstName
+	}
+	if dto.LastName != "" {
+		m.LastName = dto.LastName
+	}
+	if dto.Role != "" {
 		m.Role = dto.Role
 	}
-	if dto.Status == "" {
-		m.Status = model.StatusActive
-	} else {
+	if dto.Status != "" {
 		m.Status = dto.Status
 	}
 
@@ -65,19 +89,112 @@ func (dto UpdateUserRequest) ToDBModel(m model.User) model.User {
 type UserResponse struct {
 	ID        int64  `json:"id"`
 	Email     string `json:"email"`
+	Username  string `json:"username,omitempty"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Role      string `json:"role"`
 	Status    string `json:"status"`
+	Version   int64  `json:"version"`
+
+	PendingEmail string `json:"pending_email,omitempty"`
+
+	Phone            string `json:"phone,omitempty"`
+	PhoneVerified    bool   `json:"phone_verified"`
+	TwoFactorEnabled bool   `json:"two_factor_enabled"`
+
+	StatusReason    string     `json:"status_reason,omitempty"`
+	StatusExpiresAt *time.Time `json:"status_expires_at,omitempty"`
+
+	DeletionRequestedAt *time.Time `json:"deletion_requested_at,omitempty"`
 }
 
 func (dto UserResponse) ToResponseModel(m model.User) UserResponse {
 	dto.ID = m.ID
+	dto.Version = m.Version
 	dto.Email = m.Email
+	dto.Username = m.Username
 	dto.FirstName = m.FirstName
 	dto.LastName = m.LastName
 	dto.Role = string(m.Role)
 	dto.Status = string(m.Status)
+	dto.PendingEmail = m.PendingEmail
+	dto.Phone = m.Phone
+	dto.PhoneVerified = m.PhoneVerified()
+	dto.TwoFactorEnabled = m.TwoFactorEnabled
+	dto.StatusReason = m.StatusReason
+	dto.StatusExpiresAt = m.StatusExpiresAt
+	dto.DeletionRequestedAt = m.DeletionRequestedAt
+
+	return dto
+}
+
+type UserListResponse struct {
+	Items      []UserResponse         `json:"items"`
+	Pagination map[string]interface{} `json:"pagination"`
+}
+
+type DeletedUserResponse struct {
+	UserResponse
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+func (dto DeletedUserResponse) ToResponseModel(m model.User) DeletedUserResponse {
+	dto.UserResponse = UserResponse{}.ToResponseModel(m)
+	dto.DeletedAt = m.DeletedAt
 
 	return dto
 }
+
+type DeletedUserListResponse struct {
+	Items      []DeletedUserResponse  `json:"items"`
+	Pagination map[string]interface{} `json:"pagination"`
+}
+
+// Kullanıcının kendi hesabını silme isteği; şifre tekrar doğrulanır
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type DeleteAccountResponse struct {
+	ScheduledAt time.Time `json:"scheduled_at"` // Bu tarihten sonra kişisel veriler temizlenir
+}
+
+// E-posta değişikliği onay / geri alma linkindeki token
+type EmailChangeTokenRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Telefon numarası değişikliği; şifre tekrar doğrulanır ve numaraya SMS kodu gönderilir
+type ChangePhoneRequest struct {
+	Phone    string `json:"phone" validate:"required,e164"`
+	Password string `json:"password" validate:"required"`
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
+
+// SMS ile iki adımlı doğrulamayı açma/kapatma
+type TwoFactorRequest struct {
+	Enabled  bool   `json:"enabled"`
+	Password string `json:"password" validate:"required"`
+}
+
+// Kullanıcının kendi kullanıcı adını belirlemesi/değiştirmesi
+type ChangeUsernameRequest struct {
+	Username string `json:"username" validate:"required,max=30"`
+}
+
+type UsernameAvailabilityResponse struct {
+	Username  string `json:"username"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"` // Alınamıyorsa nedeni
+}
+
+// Admin tarafından durum değişikliği. Ban ve askı için reason zorunlu,
+// expires_at verilirse süre dolunca kullanıcı otomatik olarak aktif edilir.
+type ChangeUserStatusRequest struct {
+	Status    model.Status `json:"status" validate:"required,oneof=active inactive suspended banned"`
+	Reason    string       `json:"reason" validate:"max=500"`
+	ExpiresAt *time.Time   `json:"expires_at"`
+}
@@ -0,0 +1,190 @@
+package dto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/internal/model"
+	"github.com/Furkanturan8/goftr-template/pkg/password"
+	"github.com/go-playground/validator/v10"
+	"io"
+	"strings"
+	"time"
+)
+
+// Desteklenen içe aktarma formatları
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatJSON = "json"
+)
+
+// UserImportRow içe aktarılan dosyadaki tek bir kullanıcı satırıdır.
+// PasswordHash başka bir sistemden aktarılan hash'tir (argon2id, bcrypt veya
+// pbkdf2); verilirse şifre yerine kullanılır ve kullanıcı mevcut şifresiyle giriş yapar.
+type UserImportRow struct {
+	Line         int
+	Request      CreateUserRequest
+	PasswordHash string
+}
+
+// ParseUserImport CSV (başlık satırlı) veya JSON (obje dizisi) formatındaki
+// kullanıcı listesini okur. CSV kolonları CreateUserRequest json alan adlarıdır:
+// email, phone, username, first_name, last_name, password, role, status
+// ve ek olarak password_hash
+func ParseUserImport(format string, r io.Reader) ([]UserImportRow, error) {
+	switch strings.ToLower(format) {
+	case ImportFormatCSV:
+		return parseUserImportCSV(r)
+	case ImportFormatJSON:
+		return parseUserImportJSON(r)
+	}
+	return nil, fmt.Errorf("desteklenmeyen format: %q (csv veya json olmalı)", format)
+}
+
+func parseUserImportCSV(r io.Reader) ([]UserImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("CSV dosyası boş")
+		}
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, errors.New("CSV başlığında email kolonu bulunamadı")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []UserImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, UserImportRow{
+			Line: line,
+			Request: CreateUserRequest{
+				Email:     field(record, "email"),
+				Phone:     field(record, "phone"),
+				Username:  field(record, "username"),
+				FirstName: field(record, "first_name"),
+				LastName:  field(record, "last_name"),
+				Password:  field(record, "password"),
+				Role:      model.Role(field(record, "role")),
+				Status:    model.Status(field(record, "status")),
+			},
+			PasswordHash: field(record, "password_hash"),
+		})
+	}
+	return rows, nil
+}
+
+func parseUserImportJSON(r io.Reader) ([]UserImportRow, error) {
+	var requests []struct {
+		CreateUserRequest
+		PasswordHash string `json:"password_hash"`
+	}
+	if err := json.NewDecoder(r).Decode(&requests); err != nil {
+		return nil, fmt.Errorf("JSON okunamadı: %v", err)
+	}
+
+	rows := make([]UserImportRow, len(requests))
+	for i, req := range requests {
+		rows[i] = UserImportRow{Line: i + 1, Request: req.CreateUserRequest, PasswordHash: req.PasswordHash}
+	}
+	return rows, nil
+}
+
+// Prepare satırı CreateUserRequest kurallarıyla doğrular ve modele çevirir.
+// generatePassword true ise şifresi boş satırlara rastgele bir şifre atanır
+// (kullanıcı davet e-postasındaki link ile kendi şifresini belirler).
+func (row UserImportRow) Prepare(generatePassword bool) (model.User, []string) {
+	req := row.Request
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+
+	hashed := row.PasswordHash != ""
+	if hashed {
+		if req.Password != "" {
+			return model.User{}, []string{"Password: password ve password_hash birlikte verilemez"}
+		}
+		if err := password.Validate(row.PasswordHash); err != nil {
+			return model.User{}, []string{"PasswordHash: " + err.Error()}
+		}
+		// Şifre kuralları aktarılan hash için uygulanamaz
+		req.Password = importedHashPlaceholder
+	} else if req.Password == "" && generatePassword {
+		req.Password = randomPassword()
+	} else if violations := req.PasswordViolations(); len(violations) > 0 {
+		messages := make([]string, len(violations))
+		for i, v := range violations {
+			messages[i] = "Password: " + v.Message
+		}
+		return model.User{}, messages
+	}
+
+	if err := validate.Struct(req); err != nil {
+		var fieldErrs validator.ValidationErrors
+		if !errors.As(err, &fieldErrs) {
+			return model.User{}, []string{err.Error()}
+		}
+		messages := make([]string, len(fieldErrs))
+		for i, fe := range fieldErrs {
+			messages[i] = fmt.Sprintf("%s: %s kuralına uymuyor", fe.Field(), fe.Tag())
+		}
+		return model.User{}, messages
+	}
+	// Satırlar e-posta ile eşleştirilir ve davetler e-postayla gönderilir
+	if req.Email == "" {
+		return model.User{}, []string{"Email: import için zorunlu"}
+	}
+	if req.Username != "" {
+		if err := model.ValidateUsername(req.Username); err != nil {
+			return model.User{}, []string{"Username: " + err.Error()}
+		}
+	}
+	if req.Role != "" && req.Role != model.AdminRole && req.Role != model.UserRole {
+		return model.User{}, []string{"Role: geçersiz rol"}
+	}
+
+	if !hashed {
+		return req.ToDBModel(model.User{}), nil
+	}
+	req.Password = ""
+	user := req.ToDBModel(model.User{})
+	user.Password = row.PasswordHash
+	// Şifre yaşı aktarım zamanından itibaren sayılır
+	now := time.Now()
+	user.PasswordChangedAt = &now
+	return user, nil
+}
+
+// Hash ile aktarılan satırlarda şifre alanının validasyondan geçmesi için kullanılır
+const importedHashPlaceholder = "imported-password-hash"
+
+func randomPassword() string {
+	buf := make([]byte, 18)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,158 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/internal/model"
+	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"github.com/Furkanturan8/goftr-template/pkg/patch"
+	"github.com/go-playground/validator/v10"
+	"sort"
+	"strings"
+)
+
+var validate = validator.New()
+
+// PATCH ile değiştirilebilen kullanıcı alanları
+type userPatchDocument struct {
+	Email     string       `json:"email"`
+	FirstName string       `json:"first_name"`
+	LastName  string       `json:"last_name"`
+	Role      model.Role   `json:"role"`
+	Status    model.Status `json:"status"`
+}
+
+// Alan bazlı doğrulama kuralı ve yetki
+type patchFieldRule struct {
+	Column   string
+	Validate string
+	Nullable bool         // null gönderildiğinde alan boşaltılabilir mi
+	Roles    []model.Role // boşsa herkes değiştirebilir
+}
+
+var userPatchRules = map[string]patchFieldRule{
+	"email":      {Column: "email", Validate: "required,max=64,email"},
+	"first_name": {Column: "first_name", Validate: "max=100", Nullable: true},
+	"last_name":  {Column: "last_name", Validate: "max=100", Nullable: true},
+	"role":       {Column: "role", Validate: "required,oneof=admin user", Roles: []model.Role{model.AdminRole}},
+	// status okunabilir ama değiştirilemez; durum sadece POST /users/:id/status ile değişir
+}
+
+// UserPatchResult patch uygulandıktan sonraki kullanıcıyı ve değişen kolonları tutar
+type UserPatchResult struct {
+	User    model.User
+	Columns []string
+}
+
+// ApplyUserPatch merge patch (RFC 7396) veya JSON patch (RFC 6902) dokümanını
+// mevcut kullanıcıya uygular. Sadece değişen alanlar doğrulanır ve yetkisi
+// kontrol edilir; değişmeyen alanlar veritabanına yazılmaz.
+func ApplyUserPatch(current model.User, contentType string, body []byte, actorRole model.Role) (*UserPatchResult, error) {
+	original := userPatchDocument{
+		Email:     current.Email,
+		FirstName: current.FirstName,
+		LastName:  current.LastName,
+		Role:      current.Role,
+		Status:    current.Status,
+	}
+	originalRaw, err := json.Marshal(original)
+	if err != nil {
+		return nil, errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	patchedRaw, err := patch.Apply(contentType, originalRaw, body)
+	if err != nil {
+		return nil, errorx.Wrap(errorx.ErrInvalidRequest, err, "Patch dokümanı uygulanamadı")
+	}
+
+	var before, after map[string]json.RawMessage
+	_ = json.Unmarshal(originalRaw, &before)
+	if err = json.Unmarshal(patchedRaw, &after); err != nil {
+		return nil, errorx.Wrap(errorx.ErrInvalidRequest, err, "Patch sonucu bir obje olmalı")
+	}
+
+	// Silinen alanları null olarak değerlendir
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			after[key] = json.RawMessage("null")
+		}
+	}
+
+	keys := make([]string, 0, len(after))
+	for key := range after {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := &UserPatchResult{User: current}
+	var invalid, forbidden []string
+	for _, key := range keys {
+		value := after[key]
+		if old, ok := before[key]; ok && string(old) == string(value) {
+			continue
+		}
+
+		rule, ok := userPatchRules[key]
+		if !ok {
+			invalid = append(invalid, fmt.Sprintf("%s: değiştirilemez alan", key))
+			continue
+		}
+		if !rule.allows(actorRole) {
+			forbidden = append(forbidden, key)
+			continue
+		}
+
+		var str string
+		if string(value) == "null" {
+			if !rule.Nullable {
+				invalid = append(invalid, fmt.Sprintf("%s: boş olamaz", key))
+				continue
+			}
+		} else if err = json.Unmarshal(value, &str); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: metin olmalı", key))
+			continue
+		}
+
+		if err = validate.Var(str, rule.Validate); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %s kuralına uymuyor", key, rule.Validate))
+			continue
+		}
+
+		setUserPatchField(&result.User, key, str)
+		result.Columns = append(result.Columns, rule.Column)
+	}
+
+	if len(forbidden) > 0 {
+		return nil, errorx.WrapMsg(errorx.ErrForbidden, "Bu alanları değiştirme yetkiniz yok: "+strings.Join(forbidden, ", "))
+	}
+	if len(invalid) > 0 {
+		return nil, errorx.WrapMsg(errorx.ErrValidation, strings.Join(invalid, "; "))
+	}
+
+	return result, nil
+}
+
+func (r patchFieldRule) allows(role model.Role) bool {
+	if len(r.Roles) == 0 {
+		return true
+	}
+	for _, allowed := range r.Roles {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+func setUserPatchField(u *model.User, key, value string) {
+	switch key {
+	case "email":
+		u.Email = value
+	case "first_name":
+		u.FirstName = value
+	case "last_name":
+		u.LastName = value
+	case "role":
+		u.Role = model.Role(value)
+	}
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/internal/dto"
+	"github.com/Furkanturan8/goftr-template/internal/service"
+	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"github.com/Furkanturan8/goftr-template/pkg/response"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AccountHandler kullanıcının kendi verileri üzerindeki taleplerini (KVKK/GDPR) yönetir
+type AccountHandler struct {
+	service            *service.AccountService
+	emailChangeService *service.EmailChangeService
+	phoneService       *service.PhoneService
+}
+
+func NewAccountHandler(s *service.AccountService, emailChangeService *service.EmailChangeService, phoneService *service.PhoneService) *AccountHandler {
+	return &AccountHandler{service: s, emailChangeService: emailChangeService, phoneService: phoneService}
+}
+
+// Export kullanıcının hakkında tutulan tüm verileri indirir (format=json|zip)
+func (h *AccountHandler) Export(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int64)
+	format := strings.ToLower(c.Query("format", "json"))
+	if format != "json" && format != "zip" {
+		return errorx.WrapMsg(errorx.ErrInvalidRequest, "Format json veya zip olmalı")
+	}
+
+	data, err := h.service.Export(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("account-%d-%s", userID, data.GeneratedAt.Format("20060102"))
+	if format == "json" {
+		body, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return errorx.WrapErr(errorx.ErrInternal, err)
+		}
+		c.Attachment(filename + ".json")
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.Send(body)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", data.Profile},
+		{"sessions.json", data.Sessions},
+		{"tokens.json", data.Tokens},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return errorx.WrapErr(errorx.ErrInternal, err)
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(part.data); err != nil {
+			return errorx.WrapErr(errorx.ErrInternal, err)
+		}
+	}
+	if err = zw.Close(); err != nil {
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	c.Attachment(filename + ".zip")
+	c.Set(fiber.HeaderContentType, "application/zip")
+	return c.Send(buf.Bytes())
+}
+
+// RequestDeletion şifre doğrulaması ile hesap silme talebi oluşturur.
+// Tüm oturumlar kapatılır; hesap bekleme süresi sonunda anonimleştirilir.
+func (h *AccountHandler) RequestDeletion(c *fiber.Ctx) error {
+	var req dto.DeleteAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+	if err := validate.Struct(req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	scheduledAt, err := h.service.RequestDeletion(c.Context(), c.Locals("userID").(int64), req.Password, token)
+	if err != nil {
+		return err
+	}
+
+	return response.Accepted(c, dto.DeleteAccountResponse{ScheduledAt: scheduledAt}, "Hesap silme talebiniz alındı")
+}
+
+// CancelDeletion bekleme süresi dolmamış hesap silme talebini iptal eder
+func (h *AccountHandler) CancelDeletion(c *fiber.Ctx) error {
+	if err := h.service.CancelDeletion(c.Context(), c.Locals("userID").(int64)); err != nil {
+		return err
+	}
+
+	return response.Success(c, nil, "Hesap silme talebiniz iptal edildi")
+}
+
+// ConfirmEmailChange yeni adrese gönderilen onay linki ile e-posta değişikliğini tamamlar
+func (h *AccountHandler) ConfirmEmailChange(c *fiber.Ctx) error {
+	var req dto.EmailChangeTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+	if err := validate.Struct(req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	if err := h.emailChangeService.Confirm(c.Context(), req.Token); err != nil {
+		return err
+	}
+
+	return response.Success(c, nil, "E-posta adresiniz güncellendi")
+}
+
+// RevertEmailChange eski adrese gönderilen link ile e-posta değişikliğini geri alır
+func (h *AccountHandler) RevertEmailChange(c *fiber.Ctx) error {
+	var req dto.EmailChangeTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+	if err := validate.Struct(req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	if err := h.emailChangeService.Revert(c.Context(), req.Token); err != nil {
+		return err
+	}
+
+	return response.Success(c, nil, "E-posta değişikliği geri alındı ve tüm oturumlar kapatıldı")
+}
+
+// ChangePhone telefon numarasını değiştirir ve doğrulama kodu gönderir
+func (h *AccountHandler) ChangePhone(c *fiber.Ctx) error {
+	var req dto.ChangePhoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+	if err := validate.Struct(req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	user, err := h.phoneService.ChangePhone(c.Context(), c.Locals("userID").(int64), req.Phone, req.Password)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(c, dto.UserResponse{}.ToResponseModel(*user), "Telefonunuza doğrulama kodu gönderildi")
+}
+
+// VerifyPhone SMS kodu ile telefon numarasını doğrular
+func (h *AccountHandler) VerifyPhone(c *fiber.Ctx) error {
+	var req dto.VerifyPhoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+	if err := validate.Struct(req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	user, err := h.phoneService.VerifyPhone(c.Context(), c.Locals("userID").(int64), req.Code)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(c, dto.UserResponse{}.ToResponseModel(*user), "Telefon numaranız doğrulandı")
+}
+
+// SetTwoFactor SMS ile iki adımlı doğrulamayı açar veya kapatır
+func (h *AccountHandler) SetTwoFactor(c *fiber.Ctx) error {
+	var req dto.TwoFactorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+	if err := validate.Struct(req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	user, err := h.phoneService.SetTwoFactor(c.Context(), c.Locals("userID").(int64), req.Enabled, req.Password)
+	if err != nil {
+		return err
+	}
+
+	message := "İki adımlı doğrulama kapatıldı"
+	if user.TwoFactorEnabled {
+		message = "İki adımlı doğrulama açıldı"
+	}
+	return response.Success(c, dto.UserResponse{}.ToResponseModel(*user), message)
+}
@@ -1,15 +1,18 @@
 package handler
 
 import (
+	"context"
 	"fmt"
 	"github.com/Furkanturan8/goftr-template/internal/dto"
 	"github.com/Furkanturan8/goftr-template/internal/model"
 	"github.com/Furkanturan8/goftr-template/internal/service"
 	"github.com/Furkanturan8/goftr-template/pkg/email"
 	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"github.com/Furkanturan8/goftr-template/pkg/password"
 	"github.com/Furkanturan8/goftr-template/pkg/response"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"strconv"
 	"time"
 )
 
@@ -38,9 +41,8 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
 	}
 
-	// Şifre uzunluğu kontrolü
-	if len(req.Password) < 6 {
-		return errorx.WrapMsg(errorx.ErrInvalidRequest, "Password must be at least 6 characters")
+	if err := password.PolicyError(req.PasswordViolations()); err != nil {
+		return err
 	}
 
 	user := req.ToDBModel(model.User{})
@@ -52,6 +54,7 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 
 	resp := dto.RegisterResponse{
 		Email: user.Email,
+		Phone: user.Phone,
 	}
 	return response.Success(c, resp, "User registered successfully")
 }
@@ -67,23 +70,95 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return errorx.ErrInvalidRequest
 	}
 
-	// Context'e client bilgilerini ekle
-	ctx := c.Context()
-	ctx.SetUserValue("user_agent", c.Get("User-Agent"))
-	ctx.SetUserValue("client_ip", c.IP())
+	identifier := req.Email
+	if identifier == "" {
+		identifier = req.Username
+	}
 
-	token, err := h.authService.Login(ctx, req.Email, req.Password)
+	token, challenge, err := h.authService.Login(clientContext(c), identifier, req.Password)
 	if err != nil {
 		return err
 	}
 
-	resp := dto.LoginResponse{
+	// İki adımlı doğrulama açık: telefona kod gönderildi
+	if challenge != "" {
+		resp := dto.TwoFactorChallengeResponse{
+			ChallengeToken: challenge,
+			ExpiresIn:      int(service.TwoFactorChallengeTTL.Seconds()),
+		}
+		return response.Success(c, resp, "Telefonunuza doğrulama kodu gönderildi")
+	}
+
+	return response.Success(c, loginResponse(token), "Login successful")
+}
+
+// VerifyTwoFactor iki adımlı doğrulamada SMS kodu ile girişi tamamlar
+func (h *AuthHandler) VerifyTwoFactor(c *fiber.Ctx) error {
+	var req dto.TwoFactorVerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+	if err := validate.Struct(req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	token, err := h.authService.VerifyTwoFactor(clientContext(c), req.ChallengeToken, req.Code)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(c, loginResponse(token), "Login successful")
+}
+
+// RequestLoginCode telefona SMS ile giriş kodu gönderir
+func (h *AuthHandler) RequestLoginCode(c *fiber.Ctx) error {
+	var req dto.PhoneCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+	if err := validate.Struct(req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	if err := h.authService.RequestLoginCode(c.Context(), req.Phone); err != nil {
+		return err
+	}
+
+	return response.Success(c, nil, "Numara kayıtlıysa doğrulama kodu gönderildi")
+}
+
+// LoginWithCode SMS kodu ile giriş yapar
+func (h *AuthHandler) LoginWithCode(c *fiber.Ctx) error {
+	var req dto.PhoneLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+	if err := validate.Struct(req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	token, err := h.authService.LoginWithCode(clientContext(c), req.Phone, req.Code)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(c, loginResponse(token), "Login successful")
+}
+
+// Oturum kaydı için client bilgilerini context'e ekler
+func clientContext(c *fiber.Ctx) context.Context {
+	ctx := c.Context()
+	ctx.SetUserValue("user_agent", c.Get("User-Agent"))
+	ctx.SetUserValue("client_ip", c.IP())
+	return ctx
+}
+
+func loginResponse(token *model.Token) dto.LoginResponse {
+	return dto.LoginResponse{
 		AccessToken:  token.AccessToken,
 		RefreshToken: token.RefreshToken,
 		ExpiresIn:    int(time.Until(token.ExpiresAt).Seconds()),
 	}
-
-	return response.Success(c, resp, "Login successful")
 }
 
 func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
@@ -129,6 +204,20 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	return response.Success(c, "Logged out successfully")
 }
 
+// ForceLogout (admin) kullanıcının tüm oturumlarını ve token'larını geçersiz kılar
+func (h *AuthHandler) ForceLogout(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	if err = h.authService.ForceLogout(c.Context(), id); err != nil {
+		return err
+	}
+
+	return response.Success(c, nil, "Kullanıcının tüm oturumları sonlandırıldı")
+}
+
 func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
 	var req dto.ForgotPasswordRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -168,7 +257,7 @@ func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
 	}
 
 	if err := h.authService.ResetPassword(c.Context(), req.Token, req.NewPassword); err != nil {
-		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+		return err
 	}
 
 	return response.Success(c, "Password has been reset successfully")
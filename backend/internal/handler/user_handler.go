@@ -1,23 +1,44 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"github.com/Furkanturan8/goftr-template/internal/dto"
 	"github.com/Furkanturan8/goftr-template/internal/model"
 	"github.com/Furkanturan8/goftr-template/internal/service"
 	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"github.com/Furkanturan8/goftr-template/pkg/etag"
+	"github.com/Furkanturan8/goftr-template/pkg/export"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
+	"github.com/Furkanturan8/goftr-template/pkg/password"
+	"github.com/Furkanturan8/goftr-template/pkg/query"
 	"github.com/Furkanturan8/goftr-template/pkg/response"
+	"io"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type UserHandler struct {
-	service *service.UserService
+	service            *service.UserService
+	statusService      *service.UserStatusService
+	emailChangeService *service.EmailChangeService
+	importService      *service.UserImportService
+	exportService      *service.UserExportService
 }
 
-func NewUserHandler(s *service.UserService) *UserHandler {
-	return &UserHandler{service: s}
+func NewUserHandler(s *service.UserService, statusService *service.UserStatusService, emailChangeService *service.EmailChangeService, importService *service.UserImportService, exportService *service.UserExportService) *UserHandler {
+	return &UserHandler{
+		service:            s,
+		statusService:      statusService,
+		emailChangeService: emailChangeService,
+		importService:      importService,
+		exportService:      exportService,
+	}
 }
 
 func (h *UserHandler) Create(c *fiber.Ctx) error {
@@ -26,6 +47,12 @@ func (h *UserHandler) Create(c *fiber.Ctx) error {
 		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
 	}
 
+	if req.Password != "" {
+		if err := password.PolicyError(req.PasswordViolations()); err != nil {
+			return err
+		}
+	}
+
 	user := req.ToDBModel(model.User{})
 	if user.Password == "" { // when admin create a new user, password is empty. so we set default password
 		// maybe we can use a link to send a mail to the user to set a password
@@ -41,7 +68,12 @@ func (h *UserHandler) Create(c *fiber.Ctx) error {
 }
 
 func (h *UserHandler) List(c *fiber.Ctx) error {
-	resp, err := h.service.List(c.Context())
+	params, err := query.ParseFromContext(c)
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	resp, err := h.service.List(c.Context(), params)
 	if err != nil {
 		return errorx.WrapErr(errorx.ErrInternal, err)
 	}
@@ -52,7 +84,10 @@ func (h *UserHandler) List(c *fiber.Ctx) error {
 		users[i] = dto.UserResponse{}.ToResponseModel(user)
 	}
 
-	return response.Success(c, users)
+	return response.Success(c, dto.UserListResponse{
+		Items:      users,
+		Pagination: query.GetPaginationResponse(params.Pagination),
+	})
 }
 
 func (h *UserHandler) GetByID(c *fiber.Ctx) error {
@@ -66,6 +101,10 @@ func (h *UserHandler) GetByID(c *fiber.Ctx) error {
 		return errorx.WrapMsg(errorx.ErrNotFound, "Kullanıcı Bulunamadı!")
 	}
 
+	if notModified(c, resp.Version) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	user := dto.UserResponse{}.ToResponseModel(*resp)
 	return response.Success(c, user)
 }
@@ -81,17 +120,31 @@ func (h *UserHandler) Update(c *fiber.Ctx) error {
 		return errorx.WrapMsg(errorx.ErrNotFound, "Kullanıcı Bulunamadı!")
 	}
 
+	if err = checkIfMatch(c, currentUser.Version, true); err != nil {
+		return err
+	}
+
 	var req dto.UpdateUserRequest
 	if err = c.BodyParser(&req); err != nil {
 		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
 	}
+	// GetByID şifre alanlarını döndürmez; şifre değişiminde mevcut hash ve geçmiş gerekir
+	if req.NewPassword != "" {
+		if err = h.service.LoadCredentials(c.Context(), currentUser); err != nil {
+			return err
+		}
+	}
 
-	user := req.ToDBModel(model.User{})
+	user := req.ToDBModel(*currentUser)
 	user.ID = id
 	// Eğer şifre değiştirilmek isteniyorsa
 	if req.NewPassword != "" {
 		// Eski şifre doğrulaması zorunlu
 		if currentUser.CheckPassword(req.CurrentPassword) {
+			// Yeni şifre politikaya ve şifre geçmişine uymalı
+			if err = password.PolicyError(user.PasswordViolations(req.NewPassword)); err != nil {
+				return err
+			}
 			// Yeni şifre hashlenerek ayarlanır
 			_ = user.SetPassword(req.NewPassword)
 			fmt.Println("şifre değiştirildi")
@@ -104,25 +157,337 @@ func (h *UserHandler) Update(c *fiber.Ctx) error {
 		user.Password = currentUser.Password
 	}
 
-	if err = h.service.Update(c.Context(), id, user); err != nil {
-		return errorx.WrapErr(errorx.ErrInternal, err)
+	if err = h.service.Update(c.Context(), id, &user); err != nil {
+		return err
 	}
 
+	c.Set(fiber.HeaderETag, etag.Format(user.Version))
 	return response.Success(c, nil, "Kullanıcı başarıyla güncellendi")
 }
 
+// Patch admin için kısmi güncelleme yapar (JSON Merge Patch veya JSON Patch)
+func (h *UserHandler) Patch(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	return h.patch(c, id, true)
+}
+
+// PatchProfile kullanıcının kendi profilini kısmi olarak günceller
+func (h *UserHandler) PatchProfile(c *fiber.Ctx) error {
+	return h.patch(c, c.Locals("userID").(int64), false)
+}
+
+// admin değilse (profil güncellemesi) If-Match opsiyoneldir ve e-posta
+// değişikliği doğrudan uygulanmaz, onay bekleyen talep olarak kaydedilir
+func (h *UserHandler) patch(c *fiber.Ctx, id int64, admin bool) error {
+	currentUser, err := h.service.GetByID(c.Context(), id)
+	if err != nil {
+		return errorx.WrapMsg(errorx.ErrNotFound, "Kullanıcı Bulunamadı!")
+	}
+
+	if err = checkIfMatch(c, currentUser.Version, admin); err != nil {
+		return err
+	}
+
+	result, err := dto.ApplyUserPatch(*currentUser, c.Get(fiber.HeaderContentType), c.Body(), c.Locals("role").(model.Role))
+	if err != nil {
+		return err
+	}
+
+	message := "Kullanıcı başarıyla güncellendi"
+	update := func(ctx context.Context) error {
+		return h.service.Patch(ctx, id, &result.User, result.Columns)
+	}
+	if !admin && result.User.Email != currentUser.Email {
+		newEmail := result.User.Email
+		result.User.Email = currentUser.Email
+		result.Columns = withoutColumn(result.Columns, "email")
+		err = h.emailChangeService.UpdateAndRequest(c.Context(), &result.User, newEmail, update)
+		message = emailChangePendingMessage
+	} else {
+		err = update(c.Context())
+	}
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderETag, etag.Format(result.User.Version))
+	return response.Success(c, dto.UserResponse{}.ToResponseModel(result.User), message)
+}
+
 func (h *UserHandler) Delete(c *fiber.Ctx) error {
 	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
 	if err != nil {
 		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
 	}
 
-	if err = h.service.Delete(c.Context(), id); err != nil {
-		return errorx.WrapErr(errorx.ErrInternal, err)
+	currentUser, err := h.service.GetByID(c.Context(), id)
+	if err != nil {
+		return errorx.WrapMsg(errorx.ErrNotFound, "Silinecek kullanıcı bulunamadı")
+	}
+
+	if err = checkIfMatch(c, currentUser.Version, true); err != nil {
+		return err
+	}
+
+	if err = h.service.Delete(c.Context(), id, currentUser.Version); err != nil {
+		return err
 	}
 	return response.Success(c, nil, "Kullanıcı başarıyla silindi")
 }
 
+func (h *UserHandler) ListDeleted(c *fiber.Ctx) error {
+	params, err := query.ParseFromContext(c)
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	resp, err := h.service.ListDeleted(c.Context(), params)
+	if err != nil {
+		return err
+	}
+
+	users := make([]dto.DeletedUserResponse, len(resp))
+	for i, user := range resp {
+		users[i] = dto.DeletedUserResponse{}.ToResponseModel(user)
+	}
+
+	return response.Success(c, dto.DeletedUserListResponse{
+		Items:      users,
+		Pagination: query.GetPaginationResponse(params.Pagination),
+	})
+}
+
+func (h *UserHandler) Restore(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	if err = h.service.Restore(c.Context(), id); err != nil {
+		return err
+	}
+
+	return response.Success(c, nil, "Kullanıcı başarıyla geri yüklendi")
+}
+
+func (h *UserHandler) HardDelete(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	if err = h.service.HardDelete(c.Context(), id); err != nil {
+		return err
+	}
+
+	return response.Success(c, nil, "Kullanıcı kalıcı olarak silindi")
+}
+
+// ChangeStatus kullanıcının durumunu değiştirir (ban, askı, aktif/pasif).
+// If-Match gönderilirse kaydın güncel versiyonu ile karşılaştırılır.
+func (h *UserHandler) ChangeStatus(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	var req dto.ChangeUserStatusRequest
+	if err = c.BodyParser(&req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+	if err = validate.Struct(req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	change := service.StatusChange{
+		Status:    req.Status,
+		Reason:    req.Reason,
+		ExpiresAt: req.ExpiresAt,
+	}
+	actorID := c.Locals("userID").(int64)
+	change.ActorID = &actorID
+	if header := c.Get(fiber.HeaderIfMatch); header != "" {
+		version, ok := etag.Parse(header)
+		if !ok {
+			return errorx.ErrPreconditionFailed
+		}
+		change.Version = version
+	}
+
+	user, err := h.statusService.ChangeStatus(c.Context(), id, change)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderETag, etag.Format(user.Version))
+	return response.Success(c, dto.UserResponse{}.ToResponseModel(*user), "Kullanıcı durumu güncellendi")
+}
+
+// StatusHistory kullanıcının durum geçmişini döner
+func (h *UserHandler) StatusHistory(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	items, err := h.statusService.History(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(c, items)
+}
+
+// Import CSV veya JSON dosyasından toplu kullanıcı oluşturur.
+// Dosya multipart "file" alanı veya ham body olarak gönderilebilir.
+// Query: format=csv|json, dry_run=true, send_invites=true, batch_size=100
+func (h *UserHandler) Import(c *fiber.Ctx) error {
+	format := c.Query("format")
+	var body io.Reader
+
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+		}
+		defer f.Close()
+		body = f
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+		}
+	} else {
+		body = bytes.NewReader(c.Body())
+		if format == "" {
+			switch {
+			case strings.HasPrefix(c.Get(fiber.HeaderContentType), "text/csv"):
+				format = dto.ImportFormatCSV
+			case strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON):
+				format = dto.ImportFormatJSON
+			}
+		}
+	}
+
+	rows, err := dto.ParseUserImport(format, body)
+	if err != nil {
+		return errorx.Wrap(errorx.ErrInvalidRequest, err, "İçe aktarma dosyası okunamadı")
+	}
+
+	opts := service.ImportOptions{
+		DryRun:      c.QueryBool("dry_run"),
+		BatchSize:   c.QueryInt("batch_size"),
+		SendInvites: c.QueryBool("send_invites"),
+	}
+
+	candidates := make([]service.ImportCandidate, len(rows))
+	for i, row := range rows {
+		user, errs := row.Prepare(opts.SendInvites)
+		candidates[i] = service.ImportCandidate{Line: row.Line, User: user, Errors: errs}
+	}
+
+	report, err := h.importService.Import(c.Context(), candidates, opts)
+	if err != nil {
+		return err
+	}
+
+	if report.DryRun {
+		return response.Success(c, report, "İçe aktarma kontrolü tamamlandı")
+	}
+	return response.Success(c, report, "İçe aktarma tamamlandı")
+}
+
+// Eşiğin altındaki senkron export'lar için üst süre; daha uzun sürenler async=true ile alınmalı
+const syncExportTimeout = 2 * time.Minute
+
+// Export kullanıcı listesini csv, ndjson veya xlsx olarak indirir. List ile aynı
+// filtre ve sıralama parametrelerini alır; columns=email,first_name ile kolon seçilebilir.
+// Kayıt sayısı eşiği aşarsa veya async=true ise export arka planda hazırlanır
+// ve 202 ile durum/indirme linki döner.
+func (h *UserHandler) Export(c *fiber.Ctx) error {
+	params, err := query.ParseFromContext(c)
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	req := &service.ExportRequest{
+		Format: strings.ToLower(c.Query("format", export.FormatCSV)),
+		Params: params,
+	}
+	if columns := c.Query("columns"); columns != "" {
+		for _, column := range strings.Split(columns, ",") {
+			req.Columns = append(req.Columns, strings.TrimSpace(column))
+		}
+	}
+	if err = h.exportService.Validate(req); err != nil {
+		return err
+	}
+
+	async := c.QueryBool("async")
+	if !async {
+		if async, err = h.exportService.ShouldRunAsync(c.Context(), req); err != nil {
+			return err
+		}
+	}
+
+	if async {
+		job, err := h.exportService.Start(c.Context(), c.Locals("userID").(int64), req)
+		if err != nil {
+			return err
+		}
+		job.DownloadURL = exportURL(c, job.ID) + "/download"
+		c.Location(exportURL(c, job.ID))
+		return response.Accepted(c, job, "Export arka planda hazırlanıyor")
+	}
+
+	// Senkron export eşiğin altında kaldığı için önce bellekte hazırlanır; yarıda kalan
+	// bir export 200 ile eksik dosya olarak değil, hata yanıtı olarak döner
+	ctx, cancel := context.WithTimeout(c.UserContext(), syncExportTimeout)
+	defer cancel()
+	var buf bytes.Buffer
+	if _, err = h.exportService.Write(ctx, req, &buf); err != nil {
+		logger.Error("Kullanıcı export hatası: %v", err)
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	c.Set(fiber.HeaderContentType, export.ContentType(req.Format))
+	c.Attachment(fmt.Sprintf("users-%s.%s", time.Now().Format("20060102-150405"), req.Format))
+	return c.Send(buf.Bytes())
+}
+
+// ExportStatus arka plan export job'unun durumunu döner
+func (h *UserHandler) ExportStatus(c *fiber.Ctx) error {
+	job, err := h.exportService.GetJob(c.Context(), c.Locals("userID").(int64), c.Params("exportId"))
+	if err != nil {
+		return err
+	}
+
+	if job.Status == service.ExportStatusCompleted {
+		job.DownloadURL = exportURL(c, job.ID) + "/download"
+	}
+	return response.Success(c, job)
+}
+
+// ExportDownload tamamlanmış export dosyasını indirir
+func (h *UserHandler) ExportDownload(c *fiber.Ctx) error {
+	job, err := h.exportService.GetJob(c.Context(), c.Locals("userID").(int64), c.Params("exportId"))
+	if err != nil {
+		return err
+	}
+	if job.Status != service.ExportStatusCompleted {
+		return errorx.WrapMsg(errorx.ErrNotFound, "Export henüz hazır değil")
+	}
+
+	c.Set(fiber.HeaderContentType, export.ContentType(job.Format))
+	return c.Download(h.exportService.FilePath(job), fmt.Sprintf("users-%s.%s", job.CreatedAt.Format("20060102-150405"), job.Format))
+}
+
+func exportURL(c *fiber.Ctx, id string) string {
+	return c.BaseURL() + "/api/v1/users/exports/" + id
+}
+
 func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(int64)
 	resp, err := h.service.GetByID(c.Context(), userID)
@@ -130,34 +495,56 @@ func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
 		return errorx.WrapErr(errorx.ErrNotFound, err)
 	}
 
+	if notModified(c, resp.Version) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	user := dto.UserResponse{}.ToResponseModel(*resp)
 	return response.Success(c, user)
 }
 
 func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(int64)
-	role := c.Locals("role").(model.Role)
-	status := c.Locals("status").(model.Status)
 
 	currentUser, err := h.service.GetByID(c.Context(), userID)
 	if err != nil {
 		return errorx.WrapErr(errorx.ErrNotFound, err)
 	}
 
+	if err = checkIfMatch(c, currentUser.Version, false); err != nil {
+		return err
+	}
+
 	var req dto.UpdateUserRequest
 	if err = c.BodyParser(&req); err != nil {
 		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
 	}
+	// GetByID şifre alanlarını döndürmez; şifre değişiminde mevcut hash ve geçmiş gerekir
+	if req.NewPassword != "" {
+		if err = h.service.LoadCredentials(c.Context(), currentUser); err != nil {
+			return err
+		}
+	}
 
-	user := req.ToDBModel(model.User{})
+	user := req.ToDBModel(*currentUser)
+	// Kullanıcı kendi rolünü ve durumunu değiştiremez
 	user.ID = userID
-	user.Role = role
-	user.Status = status
+	user.Role = currentUser.Role
+	user.Status = currentUser.Status
+	// E-posta yeni adres onaylanınca, telefon SMS koduyla doğrulanınca değişir;
+	// kullanıcı adı bekleme süresi olan ayrı bir uç noktadan değiştirilir
+	user.Email = currentUser.Email
+	user.Phone = currentUser.Phone
+	user.Username = currentUser.Username
 
 	// Eğer şifre değiştirilmek isteniyorsa
 	if req.NewPassword != "" {
 		// Eski şifre doğrulaması zorunlu
 		if currentUser.CheckPassword(req.CurrentPassword) {
+			// Yeni şifre politikaya ve şifre geçmişine uymalı
+			if err = password.PolicyError(user.PasswordViolations(req.NewPassword)); err != nil {
+				return err
+			}
 			// Yeni şifre hashlenerek ayarlanır
 			_ = user.SetPassword(req.NewPassword)
 			fmt.Println("şifre değiştirildi")
@@ -170,9 +557,90 @@ func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 		user.Password = currentUser.Password
 	}
 
-	if err = h.service.Update(c.Context(), userID, user); err != nil {
-		return errorx.WrapErr(errorx.ErrInternal, err)
+	message := "Profil başarıyla güncellendi"
+	update := func(ctx context.Context) error {
+		return h.service.Update(ctx, userID, &user)
+	}
+	if req.Email != "" && req.Email != currentUser.Email {
+		err = h.emailChangeService.UpdateAndRequest(c.Context(), &user, req.Email, update)
+		message = emailChangePendingMessage
+	} else {
+		err = update(c.Context())
+	}
+	if err != nil {
+		return err
 	}
 
-	return response.Success(c, nil, "Profil başarıyla güncellendi")
+	c.Set(fiber.HeaderETag, etag.Format(user.Version))
+	return response.Success(c, nil, message)
+}
+
+// ChangeUsername kullanıcının kendi kullanıcı adını belirler veya değiştirir
+func (h *UserHandler) ChangeUsername(c *fiber.Ctx) error {
+	var req dto.ChangeUsernameRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+	if err := validate.Struct(req); err != nil {
+		return errorx.WrapErr(errorx.ErrInvalidRequest, err)
+	}
+
+	user, err := h.service.ChangeUsername(c.Context(), c.Locals("userID").(int64), req.Username)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderETag, etag.Format(user.Version))
+	return response.Success(c, dto.UserResponse{}.ToResponseModel(*user), "Kullanıcı adınız güncellendi")
+}
+
+// UsernameAvailability kayıt ve profil formları için kullanıcı adının alınıp alınamayacağını döner
+func (h *UserHandler) UsernameAvailability(c *fiber.Ctx) error {
+	username := strings.TrimSpace(c.Query("username"))
+	if username == "" {
+		return errorx.WrapMsg(errorx.ErrInvalidRequest, "username parametresi zorunlu")
+	}
+
+	available, reason, err := h.service.UsernameAvailability(c.Context(), username)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(c, dto.UsernameAvailabilityResponse{Username: username, Available: available, Reason: reason})
+}
+
+const emailChangePendingMessage = "Profil güncellendi. E-posta değişikliği için yeni adresinize onay linki gönderildi"
+
+func withoutColumn(columns []string, column string) []string {
+	result := make([]string, 0, len(columns))
+	for _, c := range columns {
+		if c != column {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// Kaydın güncel ETag'ini yazar ve If-None-Match ile eşleşiyorsa true döner
+func notModified(c *fiber.Ctx, version int64) bool {
+	tag := etag.Format(version)
+	c.Set(fiber.HeaderETag, tag)
+	return etag.Match(c.Get(fiber.HeaderIfNoneMatch), tag)
+}
+
+// If-Match başlığını kaydın güncel versiyonu ile güçlü karşılaştırmayla kontrol eder;
+// W/ ön ekli ETag'ler eşleşmez. required ise başlık gönderilmemesi 428 Precondition Required döner.
+func checkIfMatch(c *fiber.Ctx, version int64, required bool) error {
+	header := c.Get(fiber.HeaderIfMatch)
+	if header == "" {
+		if required {
+			return errorx.ErrPreconditionReq
+		}
+		return nil
+	}
+
+	if !etag.MatchStrong(header, etag.Format(version)) {
+		return errorx.ErrPreconditionFailed
+	}
+	return nil
 }
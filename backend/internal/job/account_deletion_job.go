@@ -0,0 +1,21 @@
+package job
+
+import (
+	"context"
+	"github.com/Furkanturan8/goftr-template/internal/service"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
+)
+
+// AccountDeletion bekleme süresi dolmuş hesap silme taleplerini işleyen job'u döner
+func AccountDeletion(accountService *service.AccountService) Func {
+	return func(ctx context.Context) error {
+		n, err := accountService.ProcessDeletions(ctx)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			logger.Info("Hesap silme talepleri işlendi: %d kullanıcı anonimleştirildi", n)
+		}
+		return nil
+	}
+}
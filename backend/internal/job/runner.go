@@ -0,0 +1,116 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"github.com/Furkanturan8/goftr-template/pkg/cache"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
+	"sync"
+	"time"
+)
+
+// Tekil job kilidinin süresi; job çalıştığı sürece otomatik uzatılır
+const jobLockTTL = 30 * time.Second
+
+// Func periyodik olarak çalıştırılacak iş fonksiyonudur
+type Func func(ctx context.Context) error
+
+type entry struct {
+	name      string
+	interval  time.Duration
+	fn        Func
+	exclusive bool
+}
+
+// Runner kayıtlı job'ları kendi aralıklarında arka planda çalıştırır
+type Runner struct {
+	jobs   []entry
+	wg     sync.WaitGroup
+	locker cache.Locker
+}
+
+func NewRunner() *Runner {
+	return &Runner{}
+}
+
+// WithLocker EveryExclusive ile kaydedilen job'ların instance'lar arasında
+// tek seferde çalışması için kullanılacak kilidi ayarlar
+func (r *Runner) WithLocker(locker cache.Locker) *Runner {
+	r.locker = locker
+	return r
+}
+
+// Every job'u verilen aralıkla her instance'ta çalışacak şekilde kaydeder
+func (r *Runner) Every(name string, interval time.Duration, fn Func) {
+	r.jobs = append(r.jobs, entry{name: name, interval: interval, fn: fn})
+}
+
+// EveryExclusive job'u kaydeder; her çalışmada sadece kilidi alan instance çalıştırır.
+// Locker ayarlanmamışsa Every gibi davranır.
+func (r *Runner) EveryExclusive(name string, interval time.Duration, fn Func) {
+	r.jobs = append(r.jobs, entry{name: name, interval: interval, fn: fn, exclusive: true})
+}
+
+// Start tüm job'ları başlatır. ctx iptal edilince job'lar durur.
+func (r *Runner) Start(ctx context.Context) {
+	for _, j := range r.jobs {
+		r.wg.Add(1)
+		go func(j entry) {
+			defer r.wg.Done()
+			r.loop(ctx, j)
+		}(j)
+	}
+}
+
+// Wait çalışan job'ların bitmesini bekler
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Runner) loop(ctx context.Context, j entry) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	logger.Info("Job başlatıldı: %s (%s aralıkla)", j.name, j.interval)
+	for {
+		r.run(ctx, j)
+
+		select {
+		case <-ctx.Done():
+			logger.Info("Job durduruldu: %s", j.name)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) run(ctx context.Context, j entry) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			logger.Error("Job panic (%s): %v", j.name, rec)
+		}
+	}()
+
+	if j.exclusive && r.locker != nil {
+		lease, err := cache.TryLock(ctx, r.locker, "job:"+j.name, jobLockTTL)
+		if errors.Is(err, cache.ErrLockHeld) {
+			// Başka bir instance çalıştırıyor
+			return
+		}
+		if err != nil {
+			logger.Error("Job kilidi alınamadı (%s): %v", j.name, err)
+			return
+		}
+		defer func() {
+			if err := lease.Release(context.Background()); err != nil {
+				logger.Error("Job kilidi bırakılamadı (%s): %v", j.name, err)
+			}
+		}()
+		// Kilit kaybedilirse job'un context'i iptal edilir
+		ctx = lease.Context()
+	}
+
+	if err := j.fn(ctx); err != nil {
+		logger.Error("Job hatası (%s): %v", j.name, err)
+	}
+}
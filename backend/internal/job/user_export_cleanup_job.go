@@ -0,0 +1,21 @@
+package job
+
+import (
+	"context"
+	"github.com/Furkanturan8/goftr-template/internal/service"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
+)
+
+// UserExportCleanup süresi dolmuş export dosyalarını silen job'u döner
+func UserExportCleanup(exportService *service.UserExportService) Func {
+	return func(ctx context.Context) error {
+		n, err := exportService.CleanupExpired(ctx)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			logger.Info("Süresi dolmuş export dosyaları silindi: %d", n)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,25 @@
+package job
+
+import (
+	"context"
+	"github.com/Furkanturan8/goftr-template/config"
+	"github.com/Furkanturan8/goftr-template/internal/service"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
+	"time"
+)
+
+// UserRetention saklama süresi dolmuş silinmiş kullanıcıları temizleyen job'u döner
+func UserRetention(userService *service.UserService, cfg config.RetentionConfig) Func {
+	retention := time.Duration(cfg.UserDays) * 24 * time.Hour
+
+	return func(ctx context.Context) error {
+		n, err := userService.PurgeDeleted(ctx, retention, cfg.UserMode)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			logger.Info("Silinmiş kullanıcı temizliği (%s): %d kayıt", cfg.UserMode, n)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,21 @@
+package job
+
+import (
+	"context"
+	"github.com/Furkanturan8/goftr-template/internal/service"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
+)
+
+// UserStatusExpiry süresi dolmuş ban ve askıları kaldıran job'u döner
+func UserStatusExpiry(statusService *service.UserStatusService) Func {
+	return func(ctx context.Context) error {
+		n, err := statusService.LiftExpired(ctx)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			logger.Info("Süresi dolan ban/askı kaldırıldı: %d kullanıcı", n)
+		}
+		return nil
+	}
+}
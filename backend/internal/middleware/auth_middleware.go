@@ -1,9 +1,11 @@
 package middleware
 
 import (
+	"errors"
 	"github.com/Furkanturan8/goftr-template/internal/model"
 	"github.com/Furkanturan8/goftr-template/pkg/errorx"
 	"github.com/Furkanturan8/goftr-template/pkg/jwt"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
 	"github.com/gofiber/fiber/v2"
 
 	"strings"
@@ -29,6 +31,15 @@ func AuthMiddleware() fiber.Handler {
 			return errorx.WrapMsg(errorx.ErrUnauthorized, "Geçersiz veya süresi dolmuş token")
 		}
 
+		// Ban, rol/şifre değişikliği veya zorunlu çıkış sonrası eski token'lar reddedilir.
+		// Redis'e ulaşılamazsa istek engellenmez, sadece loglanır.
+		if err = jwt.CheckRevoked(c.Context(), claims); err != nil {
+			if errors.Is(err, jwt.ErrTokenRevoked) {
+				return errorx.WrapMsg(errorx.ErrUnauthorized, "Oturumunuz sonlandırıldı, lütfen tekrar giriş yapın")
+			}
+			logger.Error("Token iptal kontrolü yapılamadı (kullanıcı %d): %v", claims.UserID, err)
+		}
+
 		// Context'e kullanıcı bilgilerini ekle
 		c.Locals("userID", claims.UserID)
 		c.Locals("role", claims.Role)
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/pkg/cache"
+	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"github.com/Furkanturan8/goftr-template/pkg/jwt"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Giriş yapmamış ve API key'i olmayan istemcilerin planı
+	AnonymousPlan = "anonymous"
+	// Politikada planı listelenmeyen tüm istemciler için limit
+	anyPlan = "*"
+)
+
+// RatePolicy bir route grubu için plan bazında limitlerdir
+type RatePolicy struct {
+	Name   string
+	limits map[string]cache.RateLimit
+}
+
+// ParseRatePolicy "plan=istek/süre" listesini okur (ör. "*=120/1m,user=300/1m").
+// Politikada olmayan ve "*" ile de karşılanmayan planlar sınırlanmaz.
+func ParseRatePolicy(name, spec string) (*RatePolicy, error) {
+	policy := &RatePolicy{Name: name, limits: make(map[string]cache.RateLimit)}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		plan, rate, ok := strings.Cut(item, "=")
+		countStr, periodStr, ok2 := strings.Cut(rate, "/")
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("%s rate limit politikası geçersiz: %q (plan=istek/süre olmalı)", name, item)
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(countStr))
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("%s rate limit politikası geçersiz istek sayısı: %q", name, item)
+		}
+		period, err := time.ParseDuration(strings.TrimSpace(periodStr))
+		if err != nil || period <= 0 {
+			return nil, fmt.Errorf("%s rate limit politikası geçersiz süre: %q", name, item)
+		}
+		policy.limits[strings.TrimSpace(plan)] = cache.RateLimit{Limit: count, Period: period}
+	}
+	return policy, nil
+}
+
+// LimitFor plana uygulanacak limiti döner; plan sınırsızsa false döner
+func (p *RatePolicy) LimitFor(plan string) (cache.RateLimit, bool) {
+	if limit, ok := p.limits[plan]; ok {
+		return limit, true
+	}
+	limit, ok := p.limits[anyPlan]
+	return limit, ok
+}
+
+// RateLimiter istekleri istemci bazında sınırlar. İstemci sırasıyla X-API-Key
+// (tanımlı key'ler), Bearer token'daki kullanıcı veya IP adresi ile belirlenir.
+// Sayaçlar cache.RateLimiter'da (Redis ise tüm instance'lar arasında) tutulur.
+type RateLimiter struct {
+	limiter cache.RateLimiter
+	apiKeys map[string]string // key -> plan
+}
+
+// NewRateLimiter apiKeys'i "key=plan" listesi olarak okur
+func NewRateLimiter(limiter cache.RateLimiter, apiKeys string) (*RateLimiter, error) {
+	rl := &RateLimiter{limiter: limiter, apiKeys: make(map[string]string)}
+	for _, item := range strings.Split(apiKeys, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, plan, ok := strings.Cut(item, "=")
+		if !ok || key == "" || plan == "" {
+			return nil, fmt.Errorf("geçersiz API key tanımı (key=plan olmalı)")
+		}
+		rl.apiKeys[key] = plan
+	}
+	return rl, nil
+}
+
+// Limit politikayı uygulayan middleware'i döner. RateLimit-* başlıkları her yanıtta,
+// Retry-After sadece 429'da gönderilir. Sayaç okunamazsa istek engellenmez.
+func (rl *RateLimiter) Limit(policy *RatePolicy) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		subject, plan := rl.identify(c)
+		limit, ok := policy.LimitFor(plan)
+		if !ok {
+			return c.Next()
+		}
+
+		res, err := rl.limiter.Allow(c.Context(), policy.Name+":"+subject, limit)
+		if err != nil {
+			logger.Error("Rate limit kontrol edilemedi (%s): %v", policy.Name, err)
+			return c.Next()
+		}
+
+		c.Set("RateLimit-Limit", strconv.Itoa(limit.Limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+		c.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Limit, ceilSeconds(limit.Period)))
+		if !res.Allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			return errorx.WrapMsg(errorx.ErrTooManyRequests, "Çok fazla istek, lütfen daha sonra tekrar deneyin")
+		}
+		return c.Next()
+	}
+}
+
+// İstemciyi ve planını belirler. Token burada sadece kimlik için okunur;
+// iptal kontrolü ve yetkilendirme AuthMiddleware'de yapılır.
+func (rl *RateLimiter) identify(c *fiber.Ctx) (string, string) {
+	if key := c.Get("X-API-Key"); key != "" {
+		if plan, ok := rl.apiKeys[key]; ok {
+			// API key cache key'lerinde açık yazılmasın
+			sum := sha256.Sum256([]byte(key))
+			return "key:" + hex.EncodeToString(sum[:8]), plan
+		}
+	}
+
+	if token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); ok {
+		if claims, err := jwt.Validate(token); err == nil {
+			return "user:" + strconv.FormatInt(claims.UserID, 10), string(claims.Role)
+		}
+	}
+
+	return "ip:" + c.IP(), AnonymousPlan
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
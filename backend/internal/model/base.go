@@ -4,7 +4,16 @@ import "time"
 
 type BaseModel struct {
 	ID        int64      `json:"id" bun:",pk,autoincrement"`
+	Version   int64      `json:"version" bun:",notnull,default:1"` // Optimistic locking (ETag) için
 	CreatedAt time.Time  `json:"created_at" bun:",nullzero,default:current_timestamp"`
 	UpdatedAt time.Time  `json:"updated_at" bun:",nullzero,default:current_timestamp"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty" bun:",soft_delete,nullzero,default:null"` // Soft delete
 }
+
+func (m *BaseModel) GetVersion() int64 {
+	return m.Version
+}
+
+func (m *BaseModel) SetVersion(version int64) {
+	m.Version = version
+}
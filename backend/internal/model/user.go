@@ -1,9 +1,11 @@
 package model
 
 import (
+	"errors"
+	"github.com/Furkanturan8/goftr-template/pkg/password"
+	"regexp"
+	"strings"
 	"time"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 type Role string
@@ -14,38 +16,167 @@ const (
 	UserRole  Role = "user"
 )
 
+// Anonimleştirilmiş kullanıcıların e-posta adresleri: deleted-<id>@anonymized.invalid
+const AnonymizedEmailDomain = "@anonymized.invalid"
+
 const (
-	StatusActive   Status = "active"
-	StatusInactive Status = "inactive"
-	StatusBanned   Status = "banned"
+	StatusActive    Status = "active"
+	StatusInactive  Status = "inactive"
+	StatusBanned    Status = "banned"
+	StatusSuspended Status = "suspended"
 )
 
 type User struct {
 	BaseModel `bun:"table:users"`
 
-	Email     string    `json:"email" bun:",unique,notnull"`
+	Email     string    `json:"email" bun:",nullzero"` // unique index sadece silinmemiş kayıtlarda; telefonla kayıtta boş olabilir
 	Password  string    `json:"-" bun:"password_hash,notnull"`
 	FirstName string    `json:"first_name"`
 	LastName  string    `json:"last_name"`
 	Role      Role      `json:"role" bun:"type:user_role,notnull,default:'user'"`
 	Status    Status    `json:"status" bun:"type:user_status,notnull,default:'active'"`
 	LastLogin time.Time `json:"last_login" bun:",nullzero"`
+
+	// Güncel durumun nedeni, değiştiren admin ve (ban/askı için) bitiş zamanı
+	StatusReason    string     `json:"status_reason,omitempty" bun:",nullzero"`
+	StatusChangedBy *int64     `json:"status_changed_by,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty" bun:",nullzero"`
+	StatusExpiresAt *time.Time `json:"status_expires_at,omitempty" bun:",nullzero"`
+
+	// Opsiyonel kullanıcı adı (büyük/küçük harf duyarsız unique); girişte e-posta yerine kullanılabilir
+	Username          string     `json:"username,omitempty" bun:",nullzero"`
+	UsernameChangedAt *time.Time `json:"username_changed_at,omitempty" bun:",nullzero"`
+
+	// E.164 formatında telefon (unique). İki adımlı doğrulama sadece doğrulanmış telefonla açılabilir.
+	Phone            string     `json:"phone,omitempty" bun:",nullzero"`
+	PhoneVerifiedAt  *time.Time `json:"phone_verified_at,omitempty" bun:",nullzero"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled" bun:",notnull"`
+
+	// Onay bekleyen yeni e-posta adresi
+	PendingEmail string `json:"pending_email,omitempty" bun:",nullzero"`
+
+	// Kullanıcının hesap silme talebi; bekleme süresi dolunca hesap anonimleştirilir
+	DeletionRequestedAt *time.Time `json:"deletion_requested_at,omitempty" bun:",nullzero"`
+
+	// Tekrar kullanılamayacak önceki şifre hash'leri (en yenisi başta) ve şifre yaşı
+	PasswordHistory   []string   `json:"-" bun:",array"`
+	PasswordChangedAt *time.Time `json:"password_changed_at,omitempty" bun:",nullzero"`
+
+	// CheckPassword hash'i güncel parametrelerle yenilediyse true; kaydedilmesi gerekir
+	passwordRehashed bool
 }
 
-func (u *User) SetPassword(password string) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// SetPassword şifreyi güncel algoritma ile hash'ler. Politikaya uygunluk burada
+// değil, şifreyi belirleyen yerde PasswordViolations ile kontrol edilir.
+// Mevcut hash şifre geçmişine eklenir.
+func (u *User) SetPassword(plain string) error {
+	hashed, err := password.Hash(plain)
 	if err != nil {
 		return err
 	}
-	u.Password = string(hashedPassword)
+
+	if u.Password != "" {
+		u.PasswordHistory = append([]string{u.Password}, u.PasswordHistory...)
+	}
+	// Geçmiş, güncel şifre ile birlikte HistorySize kadar şifreyi kapsar
+	keep := password.CurrentPolicy().HistorySize - 1
+	if keep < 0 {
+		keep = 0
+	}
+	if len(u.PasswordHistory) > keep {
+		u.PasswordHistory = u.PasswordHistory[:keep]
+	}
+
+	now := time.Now()
+	u.Password = hashed
+	u.PasswordChangedAt = &now
 	return nil
 }
 
-func (u *User) CheckPassword(password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
-	return err == nil
+// PasswordViolations yeni şifrenin politikaya ve şifre geçmişine göre ihlallerini döner
+func (u *User) PasswordViolations(plain string) []password.Violation {
+	violations := password.CheckPolicy(plain, u.Email, u.FirstName, u.LastName, u.Username)
+	if u.passwordReused(plain) {
+		violations = append(violations, password.HistoryViolation())
+	}
+	return violations
+}
+
+// Güncel şifre ve geçmişteki son HistorySize-1 şifre ile karşılaştırır
+func (u *User) passwordReused(plain string) bool {
+	size := password.CurrentPolicy().HistorySize
+	if size <= 0 {
+		return false
+	}
+
+	hashes := append([]string{u.Password}, u.PasswordHistory...)
+	if len(hashes) > size {
+		hashes = hashes[:size]
+	}
+	for _, hash := range hashes {
+		if hash == "" {
+			continue
+		}
+		if ok, _ := password.Verify(plain, hash); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// PasswordExpired şifrenin politikadaki azami süreyi aşıp aşmadığını döner
+func (u *User) PasswordExpired() bool {
+	maxAge := password.CurrentPolicy().MaxAge
+	if maxAge <= 0 || u.PasswordChangedAt == nil {
+		return false
+	}
+	return time.Since(*u.PasswordChangedAt) > maxAge
+}
+
+// CheckPassword şifreyi doğrular. Hash eski bir algoritma veya parametrelerle
+// üretilmişse doğrulama başarılı olduğunda güncel ayarlarla yeniden hash'lenir.
+func (u *User) CheckPassword(plain string) bool {
+	ok, err := password.Verify(plain, u.Password)
+	if err != nil || !ok {
+		return false
+	}
+	if password.NeedsRehash(u.Password) {
+		if hashed, err := password.Hash(plain); err == nil {
+			u.Password = hashed
+			u.passwordRehashed = true
+		}
+	}
+	return true
+}
+
+// PasswordRehashed CheckPassword şifre hash'ini yenilediyse true döner
+func (u *User) PasswordRehashed() bool {
+	return u.passwordRehashed
 }
 
 func (u *User) GetStatus() Status {
 	return u.Status
 }
+
+// Kişisel verileri temizlenmiş kullanıcı mı
+func (u *User) IsAnonymized() bool {
+	return strings.HasSuffix(u.Email, AnonymizedEmailDomain)
+}
+
+// PhoneVerified kullanıcının telefonu doğrulanmış mı
+func (u *User) PhoneVerified() bool {
+	return u.Phone != "" && u.PhoneVerifiedAt != nil
+}
+
+// DTO'lardaki e164 kuralı ile aynı desen; kolon VARCHAR(16) olduğu için uzunluk da sınırlanır
+var phonePattern = regexp.MustCompile(`^\+[1-9]?[0-9]{7,14}$`)
+
+var ErrPhoneFormat = errors.New("telefon numarası E.164 formatında olmalı (ör. +905551112233)")
+
+// ValidatePhone telefon numarasının E.164 formatında olup olmadığını kontrol eder
+func ValidatePhone(phone string) error {
+	if !phonePattern.MatchString(phone) {
+		return ErrPhoneFormat
+	}
+	return nil
+}
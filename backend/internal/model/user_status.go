@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// UserStatusHistory kullanıcının durum değişikliklerinin kaydıdır.
+// ChangedBy nil ise değişikliği sistem (ör. süresi dolan ban) yapmıştır.
+type UserStatusHistory struct {
+	ID         int64      `json:"id" bun:",pk,autoincrement"`
+	UserID     int64      `json:"user_id" bun:",notnull"`
+	FromStatus Status     `json:"from_status" bun:"type:user_status,notnull"`
+	ToStatus   Status     `json:"to_status" bun:"type:user_status,notnull"`
+	Reason     string     `json:"reason,omitempty" bun:",nullzero"`
+	ChangedBy  *int64     `json:"changed_by,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" bun:",nullzero"`
+	CreatedAt  time.Time  `json:"created_at" bun:",nullzero,notnull,default:current_timestamp"`
+}
+
+// İzin verilen durum geçişleri
+var statusTransitions = map[Status][]Status{
+	StatusActive:    {StatusInactive, StatusSuspended, StatusBanned},
+	StatusInactive:  {StatusActive, StatusSuspended, StatusBanned},
+	StatusSuspended: {StatusActive, StatusInactive, StatusBanned},
+	StatusBanned:    {StatusActive, StatusInactive},
+}
+
+// CanTransitionTo mevcut durumdan verilen duruma geçilebilir mi
+func (s Status) CanTransitionTo(to Status) bool {
+	for _, allowed := range statusTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Restrictive ban ve askı gibi neden gerektiren, süreli olabilen durumlardır
+func (s Status) Restrictive() bool {
+	return s == StatusBanned || s == StatusSuspended
+}
+
+// Valid bilinen bir durum mu
+func (s Status) Valid() bool {
+	_, ok := statusTransitions[s]
+	return ok
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+const (
+	UsernameMinLength = 3
+	UsernameMaxLength = 30
+)
+
+// Harf ile başlar; harf, rakam, nokta ve alt çizgi içerebilir; nokta veya alt çizgi ile bitemez.
+// @ ve + içeremediği için girişte e-posta ve telefondan ayırt edilebilir.
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9._]*[a-zA-Z0-9]$`)
+
+// Route, sistem veya yetki ile karıştırılabilecek, kullanılamayan isimler (küçük harf)
+var reservedUsernames = map[string]struct{}{
+	"admin": {}, "administrator": {}, "root": {}, "system": {}, "sysadmin": {},
+	"support": {}, "help": {}, "info": {}, "contact": {}, "security": {},
+	"moderator": {}, "mod": {}, "staff": {}, "official": {}, "team": {},
+	"api": {}, "www": {}, "mail": {}, "email": {}, "webmaster": {}, "postmaster": {},
+	"me": {}, "user": {}, "users": {}, "auth": {}, "login": {}, "logout": {},
+	"register": {}, "signup": {}, "settings": {}, "account": {}, "profile": {},
+	"null": {}, "undefined": {}, "anonymous": {}, "deleted": {},
+}
+
+var (
+	ErrUsernameLength   = errors.New("kullanıcı adı 3-30 karakter olmalı")
+	ErrUsernameFormat   = errors.New("kullanıcı adı harf ile başlamalı; sadece harf, rakam, nokta ve alt çizgi içerebilir ve nokta/alt çizgi ile bitemez")
+	ErrUsernameRepeat   = errors.New("kullanıcı adında art arda nokta veya alt çizgi olamaz")
+	ErrUsernameReserved = errors.New("bu kullanıcı adı kullanılamaz")
+)
+
+// ValidateUsername kullanıcı adının biçim ve ayrılmış isim kurallarına uyup uymadığını kontrol eder.
+// Benzersizlik büyük/küçük harf duyarsızdır; bu kontrol veritabanında yapılır.
+func ValidateUsername(username string) error {
+	if len(username) < UsernameMinLength || len(username) > UsernameMaxLength {
+		return ErrUsernameLength
+	}
+	if !usernamePattern.MatchString(username) {
+		return ErrUsernameFormat
+	}
+	if strings.Contains(username, "..") || strings.Contains(username, "__") ||
+		strings.Contains(username, "._") || strings.Contains(username, "_.") {
+		return ErrUsernameRepeat
+	}
+	if _, ok := reservedUsernames[strings.ToLower(username)]; ok {
+		return ErrUsernameReserved
+	}
+	return nil
+}
@@ -11,40 +11,47 @@ type IAuthRepository interface {
 	SaveToken(ctx context.Context, token *model.Token) error
 	GetTokenByRefresh(ctx context.Context, refreshToken string) (*model.Token, error)
 	RevokeToken(ctx context.Context, tokenID int64) error
+	RevokeTokensByUserID(ctx context.Context, userID int64) error
+	GetTokensByUserID(ctx context.Context, userID int64) ([]*model.Token, error)
 	CreateSession(ctx context.Context, session *model.Session) error
 	GetSessionByRefreshToken(ctx context.Context, refreshToken string) (*model.Session, error)
 	UpdateSession(ctx context.Context, session *model.Session) error
 	DeleteSession(ctx context.Context, sessionID int64) error
+	DeleteSessionsByUserID(ctx context.Context, userID int64) error
 	BlockSession(ctx context.Context, sessionID int64) error
 	GetSessionsByUserID(ctx context.Context, userID int64) ([]*model.Session, error)
 	AddToBlacklist(ctx context.Context, blacklist *model.TokenBlacklist) error
 	IsTokenBlacklisted(ctx context.Context, token string) (bool, error)
 	CleanupExpiredTokens(ctx context.Context) error
 	CleanupExpiredSessions(ctx context.Context) error
-	CreateUser(ctx context.Context, user *model.User) error
-	ExistsByEmail(ctx context.Context, email string) (bool, error)
-	GetByEmail(ctx context.Context, email string) (*model.User, error)
-	GetByID(ctx context.Context, id int64) (*model.User, error)
-	Update(ctx context.Context, user *model.User) error
 }
 
+// AuthRepository token, session ve blacklist tablolarını yönetir.
+// Kullanıcı işlemleri için IUserRepository kullanılmalı.
 type AuthRepository struct {
-	db *bun.DB
+	db        *bun.DB
+	tokens    *BaseRepository[model.Token]
+	sessions  *BaseRepository[model.Session]
+	blacklist *BaseRepository[model.TokenBlacklist]
 }
 
 func NewAuthRepository(db *bun.DB) IAuthRepository {
-	return &AuthRepository{db: db}
+	return &AuthRepository{
+		db:        db,
+		tokens:    NewBaseRepository[model.Token](db),
+		sessions:  NewBaseRepository[model.Session](db),
+		blacklist: NewBaseRepository[model.TokenBlacklist](db),
+	}
 }
 
 // Token işlemleri
 func (r *AuthRepository) SaveToken(ctx context.Context, token *model.Token) error {
-	_, err := r.db.NewInsert().Model(token).Exec(ctx)
-	return err
+	return r.tokens.Create(ctx, token)
 }
 
 func (r *AuthRepository) GetTokenByRefresh(ctx context.Context, refreshToken string) (*model.Token, error) {
 	token := new(model.Token)
-	err := r.db.NewSelect().
+	err := conn(ctx, r.db).NewSelect().
 		Model(token).
 		Where("refresh_token = ? AND revoked_at IS NULL", refreshToken).
 		Relation("User").
@@ -53,7 +60,7 @@ func (r *AuthRepository) GetTokenByRefresh(ctx context.Context, refreshToken str
 }
 
 func (r *AuthRepository) RevokeToken(ctx context.Context, tokenID int64) error {
-	_, err := r.db.NewUpdate().
+	_, err := conn(ctx, r.db).NewUpdate().
 		Model((*model.Token)(nil)).
 		Set("revoked_at = ?", time.Now()).
 		Where("id = ?", tokenID).
@@ -61,15 +68,33 @@ func (r *AuthRepository) RevokeToken(ctx context.Context, tokenID int64) error {
 	return err
 }
 
+func (r *AuthRepository) RevokeTokensByUserID(ctx context.Context, userID int64) error {
+	_, err := conn(ctx, r.db).NewUpdate().
+		Model((*model.Token)(nil)).
+		Set("revoked_at = ?", time.Now()).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Exec(ctx)
+	return err
+}
+
+func (r *AuthRepository) GetTokensByUserID(ctx context.Context, userID int64) ([]*model.Token, error) {
+	var tokens []*model.Token
+	err := conn(ctx, r.db).NewSelect().
+		Model(&tokens).
+		Where("user_id = ?", userID).
+		Order("id ASC").
+		Scan(ctx)
+	return tokens, err
+}
+
 // Session işlemleri
 func (r *AuthRepository) CreateSession(ctx context.Context, session *model.Session) error {
-	_, err := r.db.NewInsert().Model(session).Exec(ctx)
-	return err
+	return r.sessions.Create(ctx, session)
 }
 
 func (r *AuthRepository) GetSessionByRefreshToken(ctx context.Context, refreshToken string) (*model.Session, error) {
 	session := new(model.Session)
-	err := r.db.NewSelect().
+	err := conn(ctx, r.db).NewSelect().
 		Model(session).
 		Where("refresh_token = ? AND is_blocked = false", refreshToken).
 		Relation("User").
@@ -78,23 +103,23 @@ func (r *AuthRepository) GetSessionByRefreshToken(ctx context.Context, refreshTo
 }
 
 func (r *AuthRepository) UpdateSession(ctx context.Context, session *model.Session) error {
-	_, err := r.db.NewUpdate().
-		Model(session).
-		WherePK().
-		Exec(ctx)
-	return err
+	return r.sessions.Update(ctx, session)
 }
 
 func (r *AuthRepository) DeleteSession(ctx context.Context, sessionID int64) error {
-	_, err := r.db.NewDelete().
+	return r.sessions.Delete(ctx, sessionID)
+}
+
+func (r *AuthRepository) DeleteSessionsByUserID(ctx context.Context, userID int64) error {
+	_, err := conn(ctx, r.db).NewDelete().
 		Model((*model.Session)(nil)).
-		Where("id = ?", sessionID).
+		Where("user_id = ?", userID).
 		Exec(ctx)
 	return err
 }
 
 func (r *AuthRepository) BlockSession(ctx context.Context, sessionID int64) error {
-	_, err := r.db.NewUpdate().
+	_, err := conn(ctx, r.db).NewUpdate().
 		Model((*model.Session)(nil)).
 		Set("is_blocked = true").
 		Where("id = ?", sessionID).
@@ -104,7 +129,7 @@ func (r *AuthRepository) BlockSession(ctx context.Context, sessionID int64) erro
 
 func (r *AuthRepository) GetSessionsByUserID(ctx context.Context, userID int64) ([]*model.Session, error) {
 	var sessions []*model.Session
-	err := r.db.NewSelect().
+	err := conn(ctx, r.db).NewSelect().
 		Model(&sessions).
 		Where("user_id = ? AND is_blocked = false", userID).
 		Scan(ctx)
@@ -113,12 +138,11 @@ func (r *AuthRepository) GetSessionsByUserID(ctx context.Context, userID int64)
 
 // Token Blacklist işlemleri
 func (r *AuthRepository) AddToBlacklist(ctx context.Context, blacklist *model.TokenBlacklist) error {
-	_, err := r.db.NewInsert().Model(blacklist).Exec(ctx)
-	return err
+	return r.blacklist.Create(ctx, blacklist)
 }
 
 func (r *AuthRepository) IsTokenBlacklisted(ctx context.Context, token string) (bool, error) {
-	exists, err := r.db.NewSelect().
+	exists, err := conn(ctx, r.db).NewSelect().
 		Model((*model.TokenBlacklist)(nil)).
 		Where("token = ? AND expires_at > ?", token, time.Now()).
 		Exists(ctx)
@@ -127,7 +151,7 @@ func (r *AuthRepository) IsTokenBlacklisted(ctx context.Context, token string) (
 
 // Temizlik işlemleri
 func (r *AuthRepository) CleanupExpiredTokens(ctx context.Context) error {
-	_, err := r.db.NewDelete().
+	_, err := conn(ctx, r.db).NewDelete().
 		Model((*model.TokenBlacklist)(nil)).
 		Where("expires_at < ?", time.Now()).
 		Exec(ctx)
@@ -135,49 +159,9 @@ func (r *AuthRepository) CleanupExpiredTokens(ctx context.Context) error {
 }
 
 func (r *AuthRepository) CleanupExpiredSessions(ctx context.Context) error {
-	_, err := r.db.NewDelete().
+	_, err := conn(ctx, r.db).NewDelete().
 		Model((*model.Session)(nil)).
 		Where("expires_at < ?", time.Now()).
 		Exec(ctx)
 	return err
 }
-
-// User işlemleri
-func (r *AuthRepository) CreateUser(ctx context.Context, user *model.User) error {
-	_, err := r.db.NewInsert().Model(user).Exec(ctx)
-	return err
-}
-
-func (r *AuthRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
-	exists, err := r.db.NewSelect().
-		Model((*model.User)(nil)).
-		Where("email = ?", email).
-		Exists(ctx)
-	return exists, err
-}
-
-func (r *AuthRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
-	user := new(model.User)
-	err := r.db.NewSelect().
-		Model(user).
-		Where("email = ?", email).
-		Scan(ctx)
-	return user, err
-}
-
-func (r *AuthRepository) GetByID(ctx context.Context, id int64) (*model.User, error) {
-	user := new(model.User)
-	err := r.db.NewSelect().
-		Model(user).
-		Where("id = ?", id).
-		Scan(ctx)
-	return user, err
-}
-
-func (r *AuthRepository) Update(ctx context.Context, user *model.User) error {
-	_, err := r.db.NewUpdate().
-		Model(user).
-		WherePK().
-		Exec(ctx)
-	return err
-}
@@ -2,82 +2,356 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"github.com/Furkanturan8/goftr-template/pkg/query"
 	"github.com/uptrace/bun"
+	"sync/atomic"
+	"time"
 )
 
-type IBaseRepository interface {
-	List(ctx context.Context, model interface{}, params *query.Params) error
-	Get(ctx context.Context, model interface{}, id int64) error
-	Create(ctx context.Context, model interface{}) error
-	Update(ctx context.Context, model interface{}) error
-	Delete(ctx context.Context, model interface{}, id int64) error
+// Stream ile cursor'dan tek seferde okunan satır sayısı
+const streamFetchSize = 500
+
+// Aynı transaction içindeki Stream çağrılarının cursor adları çakışmasın diye kullanılır
+var streamCursorSeq atomic.Uint64
+
+// Kayıt okunduktan sonra başka biri tarafından güncellendiğinde döner
+var ErrVersionConflict = errors.New("kayıt versiyonu uyuşmuyor")
+
+// versioned optimistic locking destekleyen modellerdir (bkz. model.BaseModel)
+type versioned interface {
+	GetVersion() int64
+	SetVersion(version int64)
 }
 
-type BaseRepository struct {
+// IBaseRepository tüm modeller için ortak CRUD işlemlerini tanımlar
+type IBaseRepository[T any] interface {
+	Get(ctx context.Context, id int64) (*T, error)
+	GetBy(ctx context.Context, column string, value interface{}) (*T, error)
+	List(ctx context.Context, params *query.Params) ([]T, error)
+	Create(ctx context.Context, entity *T) error
+	Update(ctx context.Context, entity *T, columns ...string) error
+	Delete(ctx context.Context, id int64) error
+	DeleteWithVersion(ctx context.Context, id int64, version int64) error
+	ForceDelete(ctx context.Context, id int64) error
+	Exists(ctx context.Context, id int64) (bool, error)
+	ExistsBy(ctx context.Context, column string, value interface{}) (bool, error)
+	Count(ctx context.Context, params *query.Params) (int, error)
+	ListDeleted(ctx context.Context, params *query.Params) ([]T, error)
+	GetDeleted(ctx context.Context, id int64) (*T, error)
+	Restore(ctx context.Context, id int64) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	Stream(ctx context.Context, params *query.Params, columns []string, fn func(entity *T) error) error
+}
+
+// BaseRepository bun üzerinde tip güvenli ortak repository implementasyonudur.
+// Modelde `soft_delete` etiketli bir alan varsa (bkz. model.BaseModel) bun
+// select sorgularında silinmiş kayıtları otomatik olarak hariç tutar ve
+// Delete işlemini deleted_at güncellemesine çevirir.
+type BaseRepository[T any] struct {
 	db *bun.DB
 }
 
-func NewBaseRepository(db *bun.DB) BaseRepository {
-	return BaseRepository{db: db}
+func NewBaseRepository[T any](db *bun.DB) *BaseRepository[T] {
+	return &BaseRepository[T]{db: db}
 }
 
-// Liste sorgularını hazırlar ve çalıştırır
-func (r *BaseRepository) List(ctx context.Context, model interface{}, params *query.Params) error {
-	q := r.db.NewSelect().Model(model)
+// Context'te aktif transaction varsa onu kullanır
+func (r *BaseRepository[T]) conn(ctx context.Context) bun.IDB {
+	return conn(ctx, r.db)
+}
 
-	// Filtreleri uygula
-	if len(params.Filters) > 0 {
-		q = query.ApplyFilters(q, params.Filters)
+// Tekil kaydı ID ile getirir
+func (r *BaseRepository[T]) Get(ctx context.Context, id int64) (*T, error) {
+	entity := new(T)
+	err := r.conn(ctx).NewSelect().
+		Model(entity).
+		Where("?TableAlias.id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
 	}
+	return entity, nil
+}
 
-	// Sıralamayı uygula
-	if len(params.Sort) > 0 {
-		q = query.ApplySort(q, params.Sort)
+// Tekil kaydı verilen kolon değerine göre getirir
+func (r *BaseRepository[T]) GetBy(ctx context.Context, column string, value interface{}) (*T, error) {
+	entity := new(T)
+	err := r.conn(ctx).NewSelect().
+		Model(entity).
+		Where("? = ?", bun.Ident(column), value).
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
 	}
+	return entity, nil
+}
 
-	// Toplam kayıt sayısını hesapla
-	if err := query.UpdatePaginationInfo(ctx, q, &params.Pagination); err != nil {
+// Liste sorgularını query parametrelerine göre hazırlar ve çalıştırır.
+// params nil değilse sayfalama bilgisi (toplam kayıt, sayfa sayısı) güncellenir.
+func (r *BaseRepository[T]) List(ctx context.Context, params *query.Params) ([]T, error) {
+	var items []T
+	q := r.conn(ctx).NewSelect().Model(&items)
+	if err := applyParams(ctx, q, params).Scan(ctx); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Kayıt oluşturur
+func (r *BaseRepository[T]) Create(ctx context.Context, entity *T) error {
+	if v, ok := any(entity).(versioned); ok && v.GetVersion() == 0 {
+		v.SetVersion(1)
+	}
+	_, err := r.conn(ctx).NewInsert().
+		Model(entity).
+		Exec(ctx)
+	return err
+}
+
+// Kaydı günceller. Kolon verilirse sadece o kolonlar yazılır.
+// Model versiyonluysa ve versiyonu biliniyorsa güncelleme sadece veritabanındaki
+// versiyon aynıysa yapılır, aksi halde ErrVersionConflict döner.
+func (r *BaseRepository[T]) Update(ctx context.Context, entity *T, columns ...string) error {
+	q := r.conn(ctx).NewUpdate().
+		Model(entity).
+		WherePK()
+
+	v, ok := any(entity).(versioned)
+	if !ok || v.GetVersion() == 0 {
+		if len(columns) > 0 {
+			q = q.Column(columns...)
+		}
+		_, err := q.Exec(ctx)
 		return err
 	}
 
-	// Sayfalamayı uygula
-	q = query.ApplyPagination(q, params.Pagination)
+	current := v.GetVersion()
+	v.SetVersion(current + 1)
+	if len(columns) > 0 {
+		q = q.Column(append(columns, "version")...)
+	}
 
-	// Sorguyu çalıştır
-	return q.Scan(ctx)
+	res, err := q.Where("version = ?", current).Exec(ctx)
+	if err != nil {
+		v.SetVersion(current)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		v.SetVersion(current)
+		return ErrVersionConflict
+	}
+	return nil
 }
 
-// Tekil kayıt sorgularını hazırlar ve çalıştırır
-func (r *BaseRepository) Get(ctx context.Context, model interface{}, id int64) error {
-	return r.db.NewSelect().
-		Model(model).
+// Kaydı siler (model destekliyorsa soft delete)
+func (r *BaseRepository[T]) Delete(ctx context.Context, id int64) error {
+	_, err := r.conn(ctx).NewDelete().
+		Model((*T)(nil)).
 		Where("id = ?", id).
-		Scan(ctx)
-}
-
-// Kayıt oluşturur
-func (r *BaseRepository) Create(ctx context.Context, model interface{}) error {
-	_, err := r.db.NewInsert().
-		Model(model).
 		Exec(ctx)
 	return err
 }
 
-// Kayıt günceller
-func (r *BaseRepository) Update(ctx context.Context, model interface{}) error {
-	_, err := r.db.NewUpdate().
-		Model(model).
-		WherePK().
+// Kaydı sadece veritabanındaki versiyon verilen versiyonla aynıysa siler
+func (r *BaseRepository[T]) DeleteWithVersion(ctx context.Context, id int64, version int64) error {
+	res, err := r.conn(ctx).NewDelete().
+		Model((*T)(nil)).
+		Where("id = ?", id).
+		Where("version = ?", version).
 		Exec(ctx)
-	return err
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrVersionConflict
+	}
+	return nil
 }
 
-// Kayıt siler
-func (r *BaseRepository) Delete(ctx context.Context, model interface{}, id int64) error {
-	_, err := r.db.NewDelete().
-		Model(model).
+// Kaydı soft delete'i yok sayarak kalıcı olarak siler
+func (r *BaseRepository[T]) ForceDelete(ctx context.Context, id int64) error {
+	_, err := r.conn(ctx).NewDelete().
+		Model((*T)(nil)).
 		Where("id = ?", id).
+		ForceDelete().
 		Exec(ctx)
 	return err
 }
+
+// Verilen ID ile kayıt olup olmadığını kontrol eder
+func (r *BaseRepository[T]) Exists(ctx context.Context, id int64) (bool, error) {
+	return r.conn(ctx).NewSelect().
+		Model((*T)(nil)).
+		Where("?TableAlias.id = ?", id).
+		Exists(ctx)
+}
+
+// Verilen kolon değerine sahip kayıt olup olmadığını kontrol eder
+func (r *BaseRepository[T]) ExistsBy(ctx context.Context, column string, value interface{}) (bool, error) {
+	return r.conn(ctx).NewSelect().
+		Model((*T)(nil)).
+		Where("? = ?", bun.Ident(column), value).
+		Exists(ctx)
+}
+
+// Filtrelere uyan kayıt sayısını döner (sayfalama uygulanmaz)
+func (r *BaseRepository[T]) Count(ctx context.Context, params *query.Params) (int, error) {
+	q := r.conn(ctx).NewSelect().Model((*T)(nil))
+	if params != nil && len(params.Filters) > 0 {
+		q = query.ApplyFilters(q, params.Filters)
+	}
+	return q.Count(ctx)
+}
+
+// Soft delete ile silinmiş kayıtları listeler
+func (r *BaseRepository[T]) ListDeleted(ctx context.Context, params *query.Params) ([]T, error) {
+	var items []T
+	q := r.conn(ctx).NewSelect().Model(&items).WhereDeleted()
+	if err := applyParams(ctx, q, params).Scan(ctx); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Soft delete ile silinmiş tekil kaydı getirir
+func (r *BaseRepository[T]) GetDeleted(ctx context.Context, id int64) (*T, error) {
+	entity := new(T)
+	err := r.conn(ctx).NewSelect().
+		Model(entity).
+		WhereDeleted().
+		Where("?TableAlias.id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// Soft delete ile silinmiş kaydı geri yükler
+func (r *BaseRepository[T]) Restore(ctx context.Context, id int64) error {
+	q := r.conn(ctx).NewUpdate().
+		Model((*T)(nil)).
+		Set("deleted_at = NULL").
+		WhereDeleted().
+		Where("id = ?", id)
+	if _, ok := any(new(T)).(versioned); ok {
+		q = q.Set("version = version + 1")
+	}
+
+	res, err := q.Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Belirtilen tarihten önce soft delete ile silinmiş kayıtları kalıcı olarak siler
+func (r *BaseRepository[T]) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.conn(ctx).NewDelete().
+		Model((*T)(nil)).
+		WhereDeleted().
+		Where("deleted_at < ?", before).
+		ForceDelete().
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Stream filtre ve sıralamaya uyan kayıtları sunucu tarafı cursor ile
+// streamFetchSize'lık parçalar halinde okuyup tek tek fn'e verir; sonuçların
+// tamamı belleğe alınmaz. columns boşsa tüm kolonlar seçilir. Sayfalama uygulanmaz.
+// Context'te transaction varsa cursor onun içinde açılır, yoksa salt okunur
+// yeni bir transaction başlatılır.
+func (r *BaseRepository[T]) Stream(ctx context.Context, params *query.Params, columns []string, fn func(entity *T) error) error {
+	if tx, ok := TxFromContext(ctx); ok {
+		return r.stream(ctx, tx, params, columns, fn)
+	}
+	return r.db.RunInTx(ctx, &sql.TxOptions{ReadOnly: true}, func(ctx context.Context, tx bun.Tx) error {
+		return r.stream(ctx, tx, params, columns, fn)
+	})
+}
+
+// Cursor'ı verilen transaction içinde açar, okur ve iş bitince kapatır
+func (r *BaseRepository[T]) stream(ctx context.Context, tx bun.Tx, params *query.Params, columns []string, fn func(entity *T) error) (err error) {
+	q := tx.NewSelect().Model((*T)(nil))
+	if len(columns) > 0 {
+		q = q.Column(columns...)
+	}
+	if params != nil {
+		q = query.ApplySort(query.ApplyFilters(q, params.Filters), params.Sort)
+	}
+
+	cursor := fmt.Sprintf("stream_cursor_%d", streamCursorSeq.Add(1))
+	if _, err = tx.ExecContext(ctx, "DECLARE "+cursor+" NO SCROLL CURSOR FOR "+q.String()); err != nil {
+		return err
+	}
+	// Dış transaction devam ederken cursor açık kalmasın
+	defer func() {
+		if _, closeErr := tx.ExecContext(ctx, "CLOSE "+cursor); err == nil {
+			err = closeErr
+		}
+	}()
+
+	for {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf("FETCH %d FROM %s", streamFetchSize, cursor))
+		if err != nil {
+			return err
+		}
+
+		n := 0
+		for rows.Next() {
+			n++
+			entity := new(T)
+			if err = r.db.ScanRow(ctx, rows, entity); err == nil {
+				err = fn(entity)
+			}
+			if err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		if err = rows.Err(); err != nil {
+			rows.Close()
+			return err
+		}
+		rows.Close()
+
+		if n < streamFetchSize {
+			return nil
+		}
+	}
+}
+
+// Filtre, sıralama ve sayfalamayı sorguya uygular
+func applyParams(ctx context.Context, q *bun.SelectQuery, params *query.Params) *bun.SelectQuery {
+	if params == nil {
+		return q
+	}
+
+	// Filtreleri uygula
+	if len(params.Filters) > 0 {
+		q = query.ApplyFilters(q, params.Filters)
+	}
+
+	// Sıralamayı uygula
+	if len(params.Sort) > 0 {
+		q = query.ApplySort(q, params.Sort)
+	}
+
+	// Toplam kayıt sayısını hesapla
+	if err := query.UpdatePaginationInfo(ctx, q, &params.Pagination); err != nil {
+		return q.Err(err)
+	}
+
+	// Sayfalamayı uygula
+	return query.ApplyPagination(q, params.Pagination)
+}
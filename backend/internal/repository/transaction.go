@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/driver/pgdriver"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTxMaxRetries = 3
+	txRetryBaseDelay    = 20 * time.Millisecond
+)
+
+// Yeniden denenebilir Postgres hata kodları
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+type txContextKey struct{}
+type txHooksContextKey struct{}
+
+// Transaction commit edildikten sonra çalışacak fonksiyonlar
+type txHooks struct {
+	mu  sync.Mutex
+	fns []func(ctx context.Context)
+}
+
+// ITxManager birden fazla repository işlemini tek transaction içinde çalıştırır
+type ITxManager interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// TxManager aktif bun.Tx'i context üzerinden repository'lere taşır.
+// İç içe çağrılar savepoint olarak çalışır; en dıştaki transaction
+// serialization ve deadlock hatalarında baştan tekrar denenir.
+type TxManager struct {
+	db         *bun.DB
+	maxRetries int
+	opts       *sql.TxOptions
+}
+
+func NewTxManager(db *bun.DB) *TxManager {
+	return &TxManager{db: db, maxRetries: defaultTxMaxRetries}
+}
+
+// Tekrar deneme sayısını ayarlar
+func (m *TxManager) WithMaxRetries(n int) *TxManager {
+	m.maxRetries = n
+	return m
+}
+
+// Transaction seçeneklerini (izolasyon seviyesi vb.) ayarlar
+func (m *TxManager) WithOptions(opts *sql.TxOptions) *TxManager {
+	m.opts = opts
+	return m
+}
+
+func (m *TxManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Zaten bir transaction varsa savepoint aç
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.RunInTx(ctx, nil, func(ctx context.Context, sp bun.Tx) error {
+			return fn(ContextWithTx(ctx, sp))
+		})
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		// Her denemede hook'lar sıfırlanır; sadece commit edilen denemenin hook'ları çalışır
+		hooks := &txHooks{}
+		err = m.db.RunInTx(ctx, m.opts, func(txCtx context.Context, tx bun.Tx) error {
+			txCtx = context.WithValue(txCtx, txHooksContextKey{}, hooks)
+			return fn(ContextWithTx(txCtx, tx))
+		})
+		if err == nil {
+			for _, hook := range hooks.fns {
+				hook(ctx)
+			}
+			return nil
+		}
+		if attempt >= m.maxRetries || !isRetryableTxError(err) {
+			return err
+		}
+
+		// Kısa ve rastgele bir bekleme ile tekrar dene
+		delay := txRetryBaseDelay*time.Duration(1<<attempt) + time.Duration(rand.Int63n(int64(txRetryBaseDelay)))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// Context'e transaction ekler
+func ContextWithTx(ctx context.Context, tx bun.Tx) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// AfterCommit fn'i context'teki transaction commit edildikten sonra çalıştırır.
+// Transaction yoksa (veya TxManager dışında açılmışsa) fn hemen çalışır.
+// Cache invalidation gibi işlemler commit'ten önce yapılırsa başka bir istek
+// eski veriyi tekrar cache'e yazabilir.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	hooks, ok := ctx.Value(txHooksContextKey{}).(*txHooks)
+	if !ok {
+		fn(ctx)
+		return
+	}
+	hooks.mu.Lock()
+	hooks.fns = append(hooks.fns, fn)
+	hooks.mu.Unlock()
+}
+
+// Context'teki aktif transaction'ı döner
+func TxFromContext(ctx context.Context) (bun.Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(bun.Tx)
+	return tx, ok
+}
+
+// Context'te transaction varsa onu, yoksa veritabanı bağlantısını döner
+func conn(ctx context.Context, db *bun.DB) bun.IDB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return db
+}
+
+func isRetryableTxError(err error) bool {
+	var pgErr pgdriver.Error
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	switch pgErr.Field('C') {
+	case sqlStateSerializationFailure, sqlStateDeadlockDetected:
+		return true
+	}
+	return false
+}
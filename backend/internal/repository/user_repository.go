@@ -2,171 +2,480 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/Furkanturan8/goftr-template/internal/model"
 	"github.com/Furkanturan8/goftr-template/pkg/cache"
+	"github.com/Furkanturan8/goftr-template/pkg/query"
 	"github.com/uptrace/bun"
 	"time"
 )
 
 const (
-	userCacheKeyPrefix = "user:"
-	userListCacheKey   = "users:list"
-	userCacheDuration  = 24 * time.Hour
+	userCacheKeyPrefix      = "user:"
+	userEmailCacheKeyPrefix = "user:email:"
+	userListCacheKey        = "users:list"
+	userListCacheTag        = "users:list"
+	userCacheDuration       = 24 * time.Hour
+	// Süresi dolan liste sayfası yenilenirken en fazla bu kadar daha sunulur
+	userListStaleDuration = 30 * time.Second
+	// Tekil kullanıcı kayıtları; invalidation kaçırılırsa eski veri en fazla bu kadar kalır
+	userEntryCacheDuration = time.Hour
+	// Bulunamayan id/e-posta aramaları (ör. kayıtlı olmayan e-posta ile giriş denemeleri)
+	userNegativeCacheDuration = time.Minute
 )
 
+// cachedUser kullanıcının cache'teki halidir. Şifre alanları modelde json:"-" olduğu için
+// cache'e (Redis ve L1) yazılmaz; şifre kontrolleri LoadCredentials ile veritabanından okur.
+// NotFound true ise kayıt negatif cache girdisidir.
+type cachedUser struct {
+	model.User
+	NotFound bool `json:"not_found,omitempty"`
+}
+
+// Cache'ten okunan kayıtlarla aynı olsun diye veritabanından okunan kaydın da şifre alanları boşaltılır
+func withoutCredentials(user *model.User) *model.User {
+	user.Password = ""
+	user.PasswordHistory = nil
+	return user
+}
+
 type IUserRepository interface {
 	Create(ctx context.Context, user *model.User) error
+	// GetByID ve GetByEmail cache'lenir ve şifre alanlarını içermez
 	GetByID(ctx context.Context, id int64) (*model.User, error)
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	// LoadCredentials şifre hash'ini ve geçmişini veritabanından okuyup user'a yazar
+	LoadCredentials(ctx context.Context, user *model.User) error
+	GetByVerifiedPhone(ctx context.Context, phone string) (*model.User, error)
+	GetByUsername(ctx context.Context, username string) (*model.User, error)
 	Update(ctx context.Context, user *model.User) error
+	UpdateFields(ctx context.Context, user *model.User, columns ...string) error
 	Delete(ctx context.Context, id int64) error
+	DeleteWithVersion(ctx context.Context, id int64, version int64) error
 	UpdateLastLogin(ctx context.Context, id int64) error
-	List(ctx context.Context) ([]model.User, error)
+	List(ctx context.Context, params *query.Params) ([]model.User, error)
+	Count(ctx context.Context, params *query.Params) (int, error)
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	ExistsByPhone(ctx context.Context, phone string) (bool, error)
+	ExistsByUsername(ctx context.Context, username string) (bool, error)
+	ListDeleted(ctx context.Context, params *query.Params) ([]model.User, error)
+	GetDeletedByID(ctx context.Context, id int64) (*model.User, error)
+	Restore(ctx context.Context, id int64) error
+	ForceDelete(ctx context.Context, id int64) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	AnonymizeDeleted(ctx context.Context, before time.Time) (int64, error)
+	ListDeletionDue(ctx context.Context, before time.Time) ([]model.User, error)
+	Anonymize(ctx context.Context, id int64) error
+	ListStatusExpired(ctx context.Context, now time.Time) ([]model.User, error)
+	Stream(ctx context.Context, params *query.Params, columns []string, fn func(user *model.User) error) error
 }
 
 type UserRepository struct {
-	db *bun.DB
+	*BaseRepository[model.User]
+	store cache.Store
 }
 
-func NewUserRepository(db *bun.DB) IUserRepository {
-	return &UserRepository{db: db}
+func NewUserRepository(db *bun.DB, store cache.Store) IUserRepository {
+	return &UserRepository{BaseRepository: NewBaseRepository[model.User](db), store: store}
 }
 
 func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
-	_, err := r.db.NewInsert().Model(user).Exec(ctx)
-	if err != nil {
+	if err := r.BaseRepository.Create(ctx, user); err != nil {
 		return fmt.Errorf("veritabanı insert hatası: %v", err)
 	}
 
-	// Yeni kullanıcıyı cache'e ekle
-	cacheKey := fmt.Sprintf("%s%d", userCacheKeyPrefix, user.ID)
-	if err = cache.Set(ctx, cacheKey, user, userCacheDuration); err != nil {
-		// Cache hatası loglansın ama işlemi engellemeyelim
-	}
-
-	// Liste cache'ini temizle çünkü yeni kullanıcı eklendi
-	cache.Delete(ctx, userListCacheKey)
+	// E-posta için negatif cache girdisi olabilir; liste cache'i de eskidi
+	r.invalidateUser(ctx, user.ID, user.Email)
 	return nil
 }
 
+// GetByID önce cache'e bakar, yoksa veritabanından okuyup cache'e yazar.
+// Transaction içindeki okumalar commit edilmemiş veriyi görebileceği için cache'i kullanmaz.
 func (r *UserRepository) GetByID(ctx context.Context, id int64) (*model.User, error) {
-	user := model.User{}
-	err := r.db.NewSelect().Model(&user).Where("id = ?", id).Scan(ctx)
+	if _, ok := TxFromContext(ctx); ok {
+		user, err := r.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return withoutCredentials(user), nil
+	}
+
+	key := userCacheKey(id)
+	var cached cachedUser
+	if err := r.store.Get(ctx, key, &cached); err == nil {
+		if cached.NotFound {
+			return nil, sql.ErrNoRows
+		}
+		return &cached.User, nil
+	}
+
+	user, err := r.Get(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = r.store.SetWithTags(ctx, key, &cachedUser{NotFound: true}, userNegativeCacheDuration, userCacheTag(id))
+		return nil, err
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &user, nil
+	_ = r.store.SetWithTags(ctx, key, &cachedUser{User: *user}, userEntryCacheDuration, userCacheTag(id))
+	return withoutCredentials(user), nil
 }
 
+// GetByEmail e-posta -> id eşlemesini cache'ler, kullanıcının kendisi GetByID ile okunur.
+// Eşleme kullanıcının tag'i ile silinir; yine de okunan kullanıcının e-postası
+// kontrol edildiği için eski bir eşleme yanlış kullanıcı döndürmez.
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
-	var user model.User
-	err := r.db.NewSelect().Model(&user).Where("email = ?", email).Scan(ctx)
+	if _, ok := TxFromContext(ctx); ok {
+		user, err := r.GetBy(ctx, "email", email)
+		if err != nil {
+			return nil, err
+		}
+		return withoutCredentials(user), nil
+	}
+
+	key := userEmailCacheKey(email)
+	var id int64
+	if err := r.store.Get(ctx, key, &id); err == nil {
+		if id == 0 {
+			return nil, sql.ErrNoRows
+		}
+		if user, err := r.GetByID(ctx, id); err == nil && user.Email == email {
+			return user, nil
+		}
+	}
+
+	user, err := r.GetBy(ctx, "email", email)
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = r.store.Set(ctx, key, int64(0), userNegativeCacheDuration)
+		return nil, err
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &user, nil
+	tag := userCacheTag(user.ID)
+	_ = r.store.SetWithTags(ctx, key, user.ID, userEntryCacheDuration, tag)
+	_ = r.store.SetWithTags(ctx, userCacheKey(user.ID), &cachedUser{User: *user}, userEntryCacheDuration, tag)
+	return withoutCredentials(user), nil
+}
+
+// LoadCredentials şifre kontrolünden önce çağrılmalı; cache'ten okunan kayıtlar şifre alanlarını içermez
+func (r *UserRepository) LoadCredentials(ctx context.Context, user *model.User) error {
+	return r.conn(ctx).NewSelect().
+		Model(user).
+		Column("password_hash", "password_history").
+		WherePK().
+		Scan(ctx)
+}
+
+// GetByVerifiedPhone sadece numarası doğrulanmış kullanıcıyı döner. Doğrulanmamış numara
+// yazım hatası veya başkasının numarası olabileceği için kimlik olarak kullanılmaz.
+func (r *UserRepository) GetByVerifiedPhone(ctx context.Context, phone string) (*model.User, error) {
+	user := new(model.User)
+	err := r.conn(ctx).NewSelect().
+		Model(user).
+		Where("phone = ?", phone).
+		Where("phone_verified_at IS NOT NULL").
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Kullanıcı adı büyük/küçük harf duyarsız aranır (lower(username) index'i kullanılır)
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	user := new(model.User)
+	err := r.conn(ctx).NewSelect().
+		Model(user).
+		Where("lower(username) = lower(?)", username).
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 func (r *UserRepository) Update(ctx context.Context, user *model.User) error {
 	user.UpdatedAt = time.Now()
 	// Sadece değişen alanları güncelle
-	_, err := r.db.NewUpdate().
-		Model(user).
-		WherePK().
-		Column("email", "first_name", "last_name", "password_hash", "role", "status", "updated_at").
-		Exec(ctx)
+	columns := []string{"email", "pending_email", "phone", "phone_verified_at", "two_factor_enabled", "username",
+		"username_changed_at", "first_name", "last_name", "role", "status", "updated_at"}
+	// GetByID ile okunan kayıtta şifre alanları boştur; şifre sadece verildiyse yazılır
+	if user.Password != "" {
+		columns = append(columns, "password_hash", "password_history", "password_changed_at")
+	}
+	err := r.BaseRepository.Update(ctx, user, columns...)
 	if err != nil {
 		return err
 	}
 
-	// Güncellenen kullanıcıyı cache'e ekle
-	cacheKey := fmt.Sprintf("%s%d", userCacheKeyPrefix, user.ID)
-	if err = cache.Set(ctx, cacheKey, user, userCacheDuration); err != nil {
-		// Cache hatası loglansın ama işlemi engellemeyelim
-		fmt.Println("Cache güncelleme hatası:", err)
+	r.invalidateUser(ctx, user.ID, user.Email)
+	return nil
+}
+
+// Sadece verilen kolonları günceller (PATCH için)
+func (r *UserRepository) UpdateFields(ctx context.Context, user *model.User, columns ...string) error {
+	user.UpdatedAt = time.Now()
+	if err := r.BaseRepository.Update(ctx, user, append(columns, "updated_at")...); err != nil {
+		return err
 	}
 
-	// Liste cache'ini temizle çünkü kullanıcı güncellendi
-	cache.Delete(ctx, userListCacheKey)
+	// user sadece güncellenen kolonları içerebileceği için cache'e yazılmaz, silinir
+	r.invalidateUser(ctx, user.ID, user.Email)
 	return nil
 }
 
 func (r *UserRepository) Delete(ctx context.Context, id int64) error {
-	_, err := r.db.NewDelete().Model((*model.User)(nil)).Where("id = ?", id).Exec(ctx)
-	if err != nil {
+	if err := r.BaseRepository.Delete(ctx, id); err != nil {
 		return err
 	}
 
-	// Silinen kullanıcıyı cache'den sil
-	cacheKey := fmt.Sprintf("%s%d", userCacheKeyPrefix, id)
-	cache.Delete(ctx, cacheKey)
-
-	// Liste cache'ini temizle çünkü kullanıcı silindi
-	cache.Delete(ctx, userListCacheKey)
+	r.invalidateUser(ctx, id)
 	return nil
 }
 
-func (r *UserRepository) UpdateLastLogin(ctx context.Context, id int64) error {
-	user := &model.User{BaseModel: model.BaseModel{ID: id}}
-	_, err := r.db.NewUpdate().
-		Model(user).
-		Column("last_login").
-		WherePK().
-		Exec(ctx)
-
-	if err != nil {
+func (r *UserRepository) DeleteWithVersion(ctx context.Context, id int64, version int64) error {
+	if err := r.BaseRepository.DeleteWithVersion(ctx, id, version); err != nil {
 		return err
 	}
 
-	// Son giriş tarihini güncelle
-	user.LastLogin = time.Now()
+	r.invalidateUser(ctx, id)
+	return nil
+}
 
-	// Güncellenen kullanıcıyı cache'e ekle
-	cacheKey := fmt.Sprintf("%s%d", userCacheKeyPrefix, id)
-	if err = cache.Set(ctx, cacheKey, user, userCacheDuration); err != nil {
-		// Cache hatası loglansın ama işlemi engellemeyelim
+func (r *UserRepository) UpdateLastLogin(ctx context.Context, id int64) error {
+	user := &model.User{BaseModel: model.BaseModel{ID: id}, LastLogin: time.Now()}
+	if err := r.BaseRepository.Update(ctx, user, "last_login"); err != nil {
+		return err
 	}
 
+	// Cache'deki kayıt artık eski, bir sonraki okumada yenilensin
+	AfterCommit(ctx, func(ctx context.Context) {
+		_ = r.store.InvalidateTags(ctx, userCacheTag(id))
+	})
 	return nil
 }
 
-func (r *UserRepository) List(ctx context.Context) ([]model.User, error) {
-	// Önce cache'den kontrol et
-	var users []model.User
-	err := cache.Get(ctx, userListCacheKey, &users)
-	if err == nil {
-		fmt.Printf("Kullanıcılar cache'den alındı\n")
-		return users, nil
+// Liste sayfaları cache.GetOrLoad ile yüklenir: popüler bir sayfanın süresi
+// dolduğunda tüm istekler aynı anda veritabanına gitmez, kısa bir süre bayat sayfa
+// sunulurken arka planda yenilenir. Transaction içinde cache kullanılmaz.
+func (r *UserRepository) List(ctx context.Context, params *query.Params) ([]model.User, error) {
+	if _, ok := TxFromContext(ctx); ok {
+		return r.BaseRepository.List(ctx, params)
 	}
 
-	err = r.db.NewSelect().Model(&users).Scan(ctx)
+	type page struct {
+		Users      []model.User     `json:"users"`
+		Pagination query.Pagination `json:"pagination"`
+	}
+	// Sayfalama bilgisi loader'da değil döndükten sonra params'a yazılır;
+	// loader başka bir isteğin params'ı ile çalışmış olabilir
+	var paramsCopy *query.Params
+	if params != nil {
+		c := *params
+		paramsCopy = &c
+	}
+
+	cached, err := cache.GetOrLoad(ctx, r.store, userListKey(params), userCacheDuration,
+		func(ctx context.Context) (page, error) {
+			users, err := r.BaseRepository.List(ctx, paramsCopy)
+			if err != nil {
+				return page{}, err
+			}
+			p := page{Users: users}
+			if paramsCopy != nil {
+				p.Pagination = paramsCopy.Pagination
+			}
+			return p, nil
+		},
+		cache.WithStale(userListStaleDuration), cache.WithTags(userListCacheTag))
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Printf("Kullanıcılar veritabanından alındı\n")
-
-	// Cache'e kaydet
-	if err = cache.Set(ctx, userListCacheKey, &users, userCacheDuration); err != nil {
-		// Cache hatası loglansın ama işlemi engellemeyecek
-		return users, nil
+	if params != nil {
+		params.Pagination = cached.Pagination
 	}
-	return users, nil
+	return cached.Users, nil
 }
 
 func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
-	exists, err := r.db.NewSelect().
+	return r.ExistsBy(ctx, "email", email)
+}
+
+func (r *UserRepository) ExistsByPhone(ctx context.Context, phone string) (bool, error) {
+	return r.ExistsBy(ctx, "phone", phone)
+}
+
+func (r *UserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	return r.conn(ctx).NewSelect().
 		Model((*model.User)(nil)).
-		Where("email = ?", email).
+		Where("lower(username) = lower(?)", username).
 		Exists(ctx)
+}
+
+func (r *UserRepository) GetDeletedByID(ctx context.Context, id int64) (*model.User, error) {
+	return r.GetDeleted(ctx, id)
+}
 
+func (r *UserRepository) Restore(ctx context.Context, id int64) error {
+	if err := r.BaseRepository.Restore(ctx, id); err != nil {
+		return err
+	}
+
+	// Id ve e-posta için negatif cache girdileri olabilir, listelerde de tekrar görünmeli
+	var email string
+	if user, err := r.Get(ctx, id); err == nil {
+		email = user.Email
+	}
+	r.invalidateUser(ctx, id, email)
+	return nil
+}
+
+func (r *UserRepository) ForceDelete(ctx context.Context, id int64) error {
+	if err := r.BaseRepository.ForceDelete(ctx, id); err != nil {
+		return err
+	}
+
+	r.invalidateUser(ctx, id)
+	return nil
+}
+
+// Belirtilen tarihten önce silinmiş kullanıcıların kişisel verilerini temizler.
+// Kayıt (ve ona bağlı referanslar) korunur, sadece PII alanları anonimleştirilir.
+func (r *UserRepository) AnonymizeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.conn(ctx).NewUpdate().
+		Model((*model.User)(nil)).
+		Set("email = 'deleted-' || id || ?", model.AnonymizedEmailDomain).
+		Set("first_name = NULL").
+		Set("last_name = NULL").
+		Set("pending_email = NULL").
+		Set("phone = NULL").
+		Set("username = NULL").
+		Set("phone_verified_at = NULL").
+		Set("two_factor_enabled = FALSE").
+		Set("password_hash = ''").
+		Set("password_history = NULL").
+		Set("version = version + 1").
+		WhereDeleted().
+		Where("deleted_at < ?", before).
+		Where("email NOT LIKE ?", "deleted-%"+model.AnonymizedEmailDomain).
+		Exec(ctx)
 	if err != nil {
-		return false, err
+		return 0, err
 	}
+	return res.RowsAffected()
+}
 
-	return exists, nil
+// Anonimleştirilmiş kayıtlar referansları (token vb.) bozulmasın diye kalıcı silinmez
+func (r *UserRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.conn(ctx).NewDelete().
+		Model((*model.User)(nil)).
+		WhereDeleted().
+		Where("deleted_at < ?", before).
+		Where("email NOT LIKE ?", "deleted-%"+model.AnonymizedEmailDomain).
+		ForceDelete().
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Silme talebi verilen tarihten önce yapılmış ve henüz silinmemiş kullanıcıları döner
+func (r *UserRepository) ListDeletionDue(ctx context.Context, before time.Time) ([]model.User, error) {
+	var users []model.User
+	err := r.conn(ctx).NewSelect().
+		Model(&users).
+		Where("deletion_requested_at < ?", before).
+		Order("id ASC").
+		Scan(ctx)
+	return users, err
+}
+
+// Kullanıcının kişisel verilerini temizler ve kaydı soft delete ile siler.
+// ID korunduğu için kullanıcıya ait referanslar geçerli kalır.
+func (r *UserRepository) Anonymize(ctx context.Context, id int64) error {
+	_, err := r.conn(ctx).NewUpdate().
+		Model((*model.User)(nil)).
+		Set("email = 'deleted-' || id || ?", model.AnonymizedEmailDomain).
+		Set("first_name = NULL").
+		Set("last_name = NULL").
+		Set("pending_email = NULL").
+		Set("phone = NULL").
+		Set("username = NULL").
+		Set("phone_verified_at = NULL").
+		Set("two_factor_enabled = FALSE").
+		Set("password_hash = ''").
+		Set("password_history = NULL").
+		Set("deleted_at = ?", time.Now()).
+		Set("version = version + 1").
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.invalidateUser(ctx, id)
+	return nil
+}
+
+// Ban veya askı süresi dolmuş kullanıcıları döner
+func (r *UserRepository) ListStatusExpired(ctx context.Context, now time.Time) ([]model.User, error) {
+	var users []model.User
+	err := r.conn(ctx).NewSelect().
+		Model(&users).
+		Where("status IN (?)", bun.In([]model.Status{model.StatusBanned, model.StatusSuspended})).
+		Where("status_expires_at <= ?", now).
+		Order("id ASC").
+		Scan(ctx)
+	return users, err
+}
+
+// Kullanıcının tag'ini taşıyan tüm key'leri ve liste cache'ini commit'ten sonra temizler.
+// Bulunamayan e-postalar için negatif girdiler tag'siz olduğundan e-posta key'leri ayrıca silinir.
+func (r *UserRepository) invalidateUser(ctx context.Context, id int64, emails ...string) {
+	AfterCommit(ctx, func(ctx context.Context) {
+		_ = r.store.InvalidateTags(ctx, userCacheTag(id), userListCacheTag)
+		for _, email := range emails {
+			if email != "" {
+				_ = r.store.Delete(ctx, userEmailCacheKey(email))
+			}
+		}
+	})
+}
+
+func userCacheKey(id int64) string {
+	return fmt.Sprintf("%s%d", userCacheKeyPrefix, id)
+}
+
+// Kullanıcıya ait tüm cache girdilerinin (kayıt, e-posta eşlemesi) tag'i
+func userCacheTag(id int64) string {
+	return userCacheKey(id)
+}
+
+func userEmailCacheKey(email string) string {
+	return userEmailCacheKeyPrefix + email
+}
+
+// Query parametrelerine göre liste cache key'i üretir
+func userListKey(params *query.Params) string {
+	if params == nil {
+		return userListCacheKey
+	}
+	raw, _ := json.Marshal(struct {
+		Page     int            `json:"page"`
+		PageSize int            `json:"page_size"`
+		Sort     []query.Sort   `json:"sort"`
+		Filters  []query.Filter `json:"filters"`
+		Search   string         `json:"search"`
+	}{params.Pagination.Page, params.Pagination.PageSize, params.Sort, params.Filters, params.Search})
+	return fmt.Sprintf("%s:%s", userListCacheKey, raw)
 }
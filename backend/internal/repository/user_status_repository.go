@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"github.com/Furkanturan8/goftr-template/internal/model"
+	"github.com/uptrace/bun"
+)
+
+type IUserStatusRepository interface {
+	Create(ctx context.Context, history *model.UserStatusHistory) error
+	ListByUserID(ctx context.Context, userID int64) ([]model.UserStatusHistory, error)
+}
+
+// UserStatusRepository kullanıcı durum geçmişini yönetir
+type UserStatusRepository struct {
+	*BaseRepository[model.UserStatusHistory]
+}
+
+func NewUserStatusRepository(db *bun.DB) IUserStatusRepository {
+	return &UserStatusRepository{BaseRepository: NewBaseRepository[model.UserStatusHistory](db)}
+}
+
+// Kullanıcının durum geçmişini en yeniden eskiye döner
+func (r *UserStatusRepository) ListByUserID(ctx context.Context, userID int64) ([]model.UserStatusHistory, error) {
+	var items []model.UserStatusHistory
+	err := r.conn(ctx).NewSelect().
+		Model(&items).
+		Where("user_id = ?", userID).
+		Order("created_at DESC", "id DESC").
+		Scan(ctx)
+	return items, err
+}
@@ -6,11 +6,14 @@ import (
 	"github.com/Furkanturan8/goftr-template/internal/middleware"
 	"github.com/Furkanturan8/goftr-template/internal/repository"
 	"github.com/Furkanturan8/goftr-template/internal/service"
+	"github.com/Furkanturan8/goftr-template/pkg/cache"
 	"github.com/Furkanturan8/goftr-template/pkg/email"
+	applogger "github.com/Furkanturan8/goftr-template/pkg/logger"
 	"github.com/Furkanturan8/goftr-template/pkg/monitoring"
+	"github.com/Furkanturan8/goftr-template/pkg/response"
+	"github.com/Furkanturan8/goftr-template/pkg/sms"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/uptrace/bun"
@@ -18,26 +21,30 @@ import (
 )
 
 type Router struct {
-	app *fiber.App
-	db  *bun.DB
-	cfg *config.Config
+	app   *fiber.App
+	db    *bun.DB
+	cfg   *config.Config
+	sms   sms.SMSSender
+	cache cache.Store
 }
 
 var prometheusEndpoint string
 var prometheusEnabled bool
 
-func NewRouter(db *bun.DB, cfg *config.Config) *Router {
+func NewRouter(db *bun.DB, cfg *config.Config, smsSender sms.SMSSender, store cache.Store) *Router {
 	prometheusEnabled = cfg.MonitoringConfig.Prometheus.Enabled
 	prometheusEndpoint = cfg.MonitoringConfig.Prometheus.Endpoint
 
 	return &Router{
-		app: fiber.New(),
-		db:  db,
-		cfg: cfg,
+		app:   fiber.New(fiber.Config{ErrorHandler: response.ErrorHandler}),
+		db:    db,
+		cfg:   cfg,
+		sms:   smsSender,
+		cache: store,
 	}
 }
 
-func (r *Router) SetupRoutes() {
+func (r *Router) SetupRoutes() error {
 	// Prometheus'un topladığı metrikleri görüntülemek için /metrics endpoint'i
 	if prometheusEnabled {
 		r.app.Get(prometheusEndpoint, monitoring.MetricsHandler())
@@ -47,31 +54,25 @@ func (r *Router) SetupRoutes() {
 	r.app.Use(logger.New())
 	r.app.Use(recover.New())
 	r.app.Use(cors.New(cors.Config{
-		AllowOrigins: "http://localhost:63342,http://localhost:3005,http://localhost:5173",
-		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders: "Content-Type, Authorization",
-	}))
-
-	// Rate limiting middleware'i ekle (30 sn de 10 istek olsun)
-	r.app.Use(limiter.New(limiter.Config{
-		Max:        10,               // Maksimum istek sayısı
-		Expiration: 30 * time.Second, // Zaman aralığı
-		KeyGenerator: func(c *fiber.Ctx) string {
-			// /metrics endpoint'i için rate limiting'i devre dışı bırak
-			if c.Path() == prometheusEndpoint {
-				return "metrics_no_limit"
-			}
-			// Her route'u ayrı ayrı sınırla (örneğin: "/users", "/users/:id", "/auth/login")
-			return c.IP() + ":" + c.Path()
-		},
+		AllowOrigins:  "http://localhost:63342,http://localhost:3005,http://localhost:5173",
+		AllowMethods:  "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+		AllowHeaders:  "Content-Type, Authorization, If-Match, If-None-Match, X-API-Key",
+		ExposeHeaders: "ETag, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After",
 	}))
 
 	// Prometheus Middleware ekleyelim
 	r.app.Use(monitoring.PrometheusMiddleware())
 
+	// Rate limit politikaları (/metrics sınırlanmaz)
+	apiLimit, authLimit, err := r.rateLimits()
+	if err != nil {
+		return err
+	}
+
 	// API versiyonu
 	api := r.app.Group("/api")
 	v1 := api.Group("/v1")
+	v1.Use(apiLimit)
 
 	// Dış paketler emailPkg
 	emailPkg := email.NewEmail(
@@ -82,24 +83,41 @@ func (r *Router) SetupRoutes() {
 	)
 
 	// Repository'ler
-	userRepo := repository.NewUserRepository(r.db)
+	userRepo := repository.NewUserRepository(r.db, r.cache)
 	authRepo := repository.NewAuthRepository(r.db)
+	txManager := repository.NewTxManager(r.db)
 
 	// Service'ler
-	authService := service.NewAuthService(authRepo, userRepo)
-	userService := service.NewUserService(userRepo)
+	otpService := service.NewOTPService(r.sms, r.cache)
+	authService := service.NewAuthService(txManager, authRepo, userRepo, otpService)
+	userService := service.NewUserService(txManager, userRepo, authRepo)
+	emailChangeService := service.NewEmailChangeService(txManager, userService, userRepo, authRepo, emailPkg, r.cfg.AppConfig.FrontendURL)
+	userStatusService := service.NewUserStatusService(txManager, userRepo, repository.NewUserStatusRepository(r.db), emailPkg)
+	userImportService := service.NewUserImportService(txManager, userRepo, emailPkg, r.cfg.AppConfig.FrontendURL)
+	phoneService := service.NewPhoneService(userRepo, otpService)
+	accountService := service.NewAccountService(txManager, userRepo, authRepo, time.Duration(r.cfg.RetentionConfig.AccountDeletionDays)*24*time.Hour)
+	exportTTL := time.Duration(r.cfg.ExportConfig.TTLHours) * time.Hour
+	userExportService := service.NewUserExportService(userRepo, r.cache, r.cfg.ExportConfig.Dir, r.cfg.ExportConfig.AsyncThreshold, exportTTL)
 
 	// Handler'lar
 	authHandler := handler.NewAuthHandler(authService, emailPkg)
-	userHandler := handler.NewUserHandler(userService)
+	accountHandler := handler.NewAccountHandler(accountService, emailChangeService, phoneService)
+	userHandler := handler.NewUserHandler(userService, userStatusService, emailChangeService, userImportService, userExportService)
 
 	// Auth routes
 	auth := v1.Group("/auth")
+	auth.Use(authLimit) // Giriş ve SMS kodu denemeleri için daha sıkı limit
 	auth.Post("/register", authHandler.Register)
 	auth.Post("/login", authHandler.Login)
+	auth.Post("/login/2fa", authHandler.VerifyTwoFactor)
+	auth.Post("/otp/request", authHandler.RequestLoginCode)
+	auth.Post("/otp/login", authHandler.LoginWithCode)
 	auth.Post("/refresh", authHandler.RefreshToken)
 	auth.Post("/forgot-password", authHandler.ForgotPassword)
 	auth.Post("/reset-password", authHandler.ResetPassword)
+	auth.Post("/confirm-email", accountHandler.ConfirmEmailChange)
+	auth.Post("/revert-email", accountHandler.RevertEmailChange)
+	auth.Get("/username-available", userHandler.UsernameAvailability)
 	auth.Post("/logout", middleware.AuthMiddleware(), authHandler.Logout)
 
 	// User routes - Base group
@@ -110,17 +128,67 @@ func (r *Router) SetupRoutes() {
 	userProfile.Use(middleware.AuthMiddleware()) // Sadece authentication gerekli
 	userProfile.Get("/", userHandler.GetProfile)
 	userProfile.Put("/", userHandler.UpdateProfile)
+	userProfile.Patch("/", userHandler.PatchProfile)
+	userProfile.Delete("/", accountHandler.RequestDeletion)
+	userProfile.Delete("/deletion", accountHandler.CancelDeletion)
+	userProfile.Get("/export", accountHandler.Export)
+	userProfile.Put("/phone", accountHandler.ChangePhone)
+	userProfile.Post("/phone/verify", accountHandler.VerifyPhone)
+	userProfile.Put("/two-factor", accountHandler.SetTwoFactor)
+	userProfile.Put("/username", userHandler.ChangeUsername)
 
 	// Admin only routes
 	adminUsers := users.Group("/")
 	adminUsers.Use(middleware.AuthMiddleware(), middleware.AdminOnly()) // Admin yetkisi gerekli
 	adminUsers.Post("/", userHandler.Create)
 	adminUsers.Get("/", userHandler.List)
+	adminUsers.Post("/import", userHandler.Import)
+	adminUsers.Get("/export", userHandler.Export)
+	adminUsers.Get("/exports/:exportId", userHandler.ExportStatus)
+	adminUsers.Get("/exports/:exportId/download", userHandler.ExportDownload)
+	adminUsers.Get("/trash", userHandler.ListDeleted)
+	adminUsers.Post("/:id/restore", userHandler.Restore)
+	adminUsers.Delete("/:id/permanent", userHandler.HardDelete)
 	adminUsers.Get("/:id", userHandler.GetByID)
 	adminUsers.Put("/:id", userHandler.Update)
+	adminUsers.Patch("/:id", userHandler.Patch)
+	adminUsers.Post("/:id/status", userHandler.ChangeStatus)
+	adminUsers.Get("/:id/status-history", userHandler.StatusHistory)
+	adminUsers.Post("/:id/logout", authHandler.ForceLogout)
 	adminUsers.Delete("/:id", userHandler.Delete)
 
 	// Diğer route grupları buraya eklenecek
+	return nil
+}
+
+// API ve auth grupları için rate limit middleware'lerini oluşturur.
+// Sayaçlar cache store'unda tutulur; store desteklemiyorsa (none) instance içi sayaç kullanılır.
+func (r *Router) rateLimits() (fiber.Handler, fiber.Handler, error) {
+	cfg := r.cfg.RateLimitConfig
+	if !cfg.Enabled {
+		next := func(c *fiber.Ctx) error { return c.Next() }
+		return next, next, nil
+	}
+
+	apiPolicy, err := middleware.ParseRatePolicy("api", cfg.API)
+	if err != nil {
+		return nil, nil, err
+	}
+	authPolicy, err := middleware.ParseRatePolicy("auth", cfg.Auth)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	backend, ok := r.cache.(cache.RateLimiter)
+	if !ok {
+		applogger.Info("Cache store rate limit desteklemiyor, limitler instance bazında tutulacak")
+		backend = cache.NewMemoryCache(0)
+	}
+	limiter, err := middleware.NewRateLimiter(backend, cfg.APIKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+	return limiter.Limit(apiPolicy), limiter.Limit(authPolicy), nil
 }
 
 func (r *Router) GetApp() *fiber.App {
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"github.com/Furkanturan8/goftr-template/internal/model"
+	"github.com/Furkanturan8/goftr-template/internal/repository"
+	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
+	"time"
+)
+
+// Hesap silme talebinde mevcut access token'ın blacklist'te kalacağı süre (bkz. AuthService.Logout)
+const accessTokenBlacklistDuration = 24 * time.Hour
+
+// AccountExport kullanıcı hakkında tutulan tüm verilerdir (KVKK/GDPR veri talebi).
+// Token ve session gizli değerleri (access/refresh token) dışa aktarılmaz.
+type AccountExport struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Profile     model.User       `json:"profile"`
+	Sessions    []AccountSession `json:"sessions"`
+	Tokens      []AccountToken   `json:"tokens"`
+}
+
+type AccountSession struct {
+	ID        int64     `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIP  string    `json:"client_ip"`
+	IsBlocked bool      `json:"is_blocked"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type AccountToken struct {
+	ID        int64      `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// AccountService kullanıcının kendi hesabı üzerindeki veri talebi işlemlerini yürütür
+type AccountService struct {
+	txManager     repository.ITxManager
+	userRepo      repository.IUserRepository
+	authRepo      repository.IAuthRepository
+	deletionDelay time.Duration
+}
+
+func NewAccountService(tx repository.ITxManager, u repository.IUserRepository, a repository.IAuthRepository, deletionDelay time.Duration) *AccountService {
+	return &AccountService{
+		txManager:     tx,
+		userRepo:      u,
+		authRepo:      a,
+		deletionDelay: deletionDelay,
+	}
+}
+
+// Export kullanıcının profilini, oturumlarını ve token geçmişini döner
+func (s *AccountService) Export(ctx context.Context, userID int64) (*AccountExport, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errorx.WrapMsg(errorx.ErrNotFound, "Kullanıcı bulunamadı")
+	}
+
+	sessions, err := s.authRepo.GetSessionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, errorx.WrapErr(errorx.ErrInternal, err)
+	}
+	tokens, err := s.authRepo.GetTokensByUserID(ctx, userID)
+	if err != nil {
+		return nil, errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	data := &AccountExport{
+		GeneratedAt: time.Now(),
+		Profile:     *user,
+		Sessions:    make([]AccountSession, len(sessions)),
+		Tokens:      make([]AccountToken, len(tokens)),
+	}
+	for i, session := range sessions {
+		data.Sessions[i] = AccountSession{
+			ID:        session.ID,
+			UserAgent: session.UserAgent,
+			ClientIP:  session.ClientIP,
+			IsBlocked: session.IsBlocked,
+			ExpiresAt: session.ExpiresAt,
+			CreatedAt: session.CreatedAt,
+			UpdatedAt: session.UpdatedAt,
+		}
+	}
+	for i, token := range tokens {
+		data.Tokens[i] = AccountToken{
+			ID:        token.ID,
+			CreatedAt: token.CreatedAt,
+			ExpiresAt: token.ExpiresAt,
+		}
+		if token.IsRevoked() {
+			revokedAt := token.RevokedAt
+			data.Tokens[i].RevokedAt = &revokedAt
+		}
+	}
+
+	return data, nil
+}
+
+// RequestDeletion şifre doğrulandıktan sonra hesap silme talebini kaydeder, tüm
+// oturumları kapatır ve token'ları iptal eder. Hesap bekleme süresi sonunda
+// anonimleştirilir; bu süre içinde tekrar giriş yapıp talep iptal edilebilir.
+func (s *AccountService) RequestDeletion(ctx context.Context, userID int64, password, accessToken string) (time.Time, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return time.Time{}, errorx.WrapMsg(errorx.ErrNotFound, "Kullanıcı bulunamadı")
+	}
+	if err = s.userRepo.LoadCredentials(ctx, user); err != nil {
+		return time.Time{}, errorx.WrapErr(errorx.ErrInternal, err)
+	}
+	if !user.CheckPassword(password) {
+		return time.Time{}, errorx.WrapMsg(errorx.ErrInvalidCredentials, "Girdiğiniz şifre yanlış")
+	}
+	if user.DeletionRequestedAt != nil {
+		return time.Time{}, errorx.WrapMsg(errorx.ErrDuplicate, "Hesap silme talebi zaten mevcut")
+	}
+
+	now := time.Now()
+	user.DeletionRequestedAt = &now
+
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.UpdateFields(ctx, user, "deletion_requested_at"); err != nil {
+			return err
+		}
+		if err := s.authRepo.RevokeTokensByUserID(ctx, userID); err != nil {
+			return err
+		}
+		if err := s.authRepo.DeleteSessionsByUserID(ctx, userID); err != nil {
+			return err
+		}
+		// Mevcut access token süresi dolana kadar geçerli kalmasın
+		return s.authRepo.AddToBlacklist(ctx, &model.TokenBlacklist{
+			Token:     accessToken,
+			ExpiresAt: now.Add(accessTokenBlacklistDuration),
+		})
+	})
+	if err != nil {
+		return time.Time{}, wrapUpdateErr(err)
+	}
+	revokeUserTokens(ctx, userID)
+
+	return now.Add(s.deletionDelay), nil
+}
+
+// CancelDeletion bekleme süresi dolmamış hesap silme talebini iptal eder
+func (s *AccountService) CancelDeletion(ctx context.Context, userID int64) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errorx.WrapMsg(errorx.ErrNotFound, "Kullanıcı bulunamadı")
+	}
+	if user.DeletionRequestedAt == nil {
+		return errorx.WrapMsg(errorx.ErrNotFound, "Bekleyen hesap silme talebi yok")
+	}
+
+	user.DeletionRequestedAt = nil
+	if err = s.userRepo.UpdateFields(ctx, user, "deletion_requested_at"); err != nil {
+		return wrapUpdateErr(err)
+	}
+	return nil
+}
+
+// ProcessDeletions bekleme süresi dolmuş talepleri işler: kullanıcının oturumları
+// silinir, token'ları iptal edilir ve kişisel verileri anonimleştirilir.
+// Kullanıcı kaydı (ID) korunduğu için ona bağlı referanslar geçerli kalır.
+func (s *AccountService) ProcessDeletions(ctx context.Context) (int, error) {
+	users, err := s.userRepo.ListDeletionDue(ctx, time.Now().Add(-s.deletionDelay))
+	if err != nil {
+		return 0, errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	processed := 0
+	for _, user := range users {
+		err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+			if err := s.authRepo.DeleteSessionsByUserID(ctx, user.ID); err != nil {
+				return err
+			}
+			if err := s.authRepo.RevokeTokensByUserID(ctx, user.ID); err != nil {
+				return err
+			}
+			return s.userRepo.Anonymize(ctx, user.ID)
+		})
+		if err != nil {
+			logger.Error("Hesap silme talebi işlenemedi (kullanıcı %d): %v", user.ID, err)
+			continue
+		}
+		processed++
+	}
+
+	return processed, nil
+}
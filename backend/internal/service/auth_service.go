@@ -6,53 +6,182 @@ import (
 	"github.com/Furkanturan8/goftr-template/internal/repository"
 	"github.com/Furkanturan8/goftr-template/pkg/errorx"
 	"github.com/Furkanturan8/goftr-template/pkg/jwt"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
+	"github.com/Furkanturan8/goftr-template/pkg/password"
+	"strings"
 	"time"
 )
 
+// İki adımlı doğrulamada SMS kodunun girilmesi için verilen süre
+const TwoFactorChallengeTTL = 5 * time.Minute
+
 type AuthService struct {
-	authRepo repository.IAuthRepository
-	userRepo repository.IUserRepository
+	txManager  repository.ITxManager
+	authRepo   repository.IAuthRepository
+	userRepo   repository.IUserRepository
+	otpService *OTPService
 }
 
-func NewAuthService(a repository.IAuthRepository, u repository.IUserRepository) *AuthService {
+func NewAuthService(tx repository.ITxManager, a repository.IAuthRepository, u repository.IUserRepository, otp *OTPService) *AuthService {
 	return &AuthService{
-		authRepo: a,
-		userRepo: u,
+		txManager:  tx,
+		authRepo:   a,
+		userRepo:   u,
+		otpService: otp,
 	}
 }
 
 func (s *AuthService) Register(ctx context.Context, user model.User) error {
 	// Email kontrolü
-	exists, err := s.userRepo.ExistsByEmail(ctx, user.Email)
-	if err != nil {
-		return errorx.WrapErr(errorx.ErrInternal, err)
+	if user.Email != "" {
+		exists, err := s.userRepo.ExistsByEmail(ctx, user.Email)
+		if err != nil {
+			return errorx.WrapErr(errorx.ErrInternal, err)
+		}
+		if exists {
+			return errorx.WrapMsg(errorx.ErrDuplicate, "Bu e-posta adresi zaten kullanımda")
+		}
 	}
-	if exists {
-		return errorx.WrapMsg(errorx.ErrDuplicate, "Bu e-posta adresi zaten kullanımda")
+	// Telefon kontrolü
+	if user.Phone != "" {
+		exists, err := s.userRepo.ExistsByPhone(ctx, user.Phone)
+		if err != nil {
+			return errorx.WrapErr(errorx.ErrInternal, err)
+		}
+		if exists {
+			return errorx.WrapMsg(errorx.ErrDuplicate, "Bu telefon numarası zaten kullanımda")
+		}
+	}
+
+	if err := checkUsernameAvailable(ctx, s.userRepo, user.Username); err != nil {
+		return err
 	}
 
 	// Kullanıcıyı kaydet
-	if err = s.userRepo.Create(ctx, &user); err != nil {
+	if err := s.userRepo.Create(ctx, &user); err != nil {
 		return errorx.WrapErr(errorx.ErrInternal, err)
 	}
 
 	return nil
 }
 
-func (s *AuthService) Login(ctx context.Context, email, password string) (*model.Token, error) {
-	user, err := s.userRepo.GetByEmail(ctx, email)
-	if err != nil {
-		return nil, errorx.WrapMsg(errorx.ErrNotFound, "Bu e-posta adresi ile kayıtlı kullanıcı bulunamadı")
+// Login e-posta veya kullanıcı adı ve şifre ile giriş yapar. Kullanıcı adları @
+// içeremediği için identifier @ içeriyorsa e-posta kabul edilir. Kullanıcının iki
+// adımlı doğrulaması açıksa token yerine telefona SMS kodu gönderilir ve
+// VerifyTwoFactor ile kullanılacak challenge token döner.
+func (s *AuthService) Login(ctx context.Context, identifier, password string) (*model.Token, string, error) {
+	var (
+		user *model.User
+		err  error
+	)
+	if strings.Contains(identifier, "@") {
+		if user, err = s.userRepo.GetByEmail(ctx, identifier); err != nil {
+			return nil, "", errorx.WrapMsg(errorx.ErrNotFound, "Bu e-posta adresi ile kayıtlı kullanıcı bulunamadı")
+		}
+	} else if user, err = s.userRepo.GetByUsername(ctx, identifier); err != nil {
+		return nil, "", errorx.WrapMsg(errorx.ErrNotFound, "Bu kullanıcı adı ile kayıtlı kullanıcı bulunamadı")
 	}
 
+	if err = s.userRepo.LoadCredentials(ctx, user); err != nil {
+		return nil, "", errorx.WrapErr(errorx.ErrInternal, err)
+	}
 	if !user.CheckPassword(password) {
-		return nil, errorx.WrapMsg(errorx.ErrInvalidCredentials, "Girdiğiniz şifre yanlış")
+		return nil, "", errorx.WrapMsg(errorx.ErrInvalidCredentials, "Girdiğiniz şifre yanlış")
+	}
+
+	// Eski algoritma/parametrelerle üretilmiş hash yenilendi; hata girişi engellemez
+	if user.PasswordRehashed() {
+		if err = s.userRepo.UpdateFields(ctx, user, "password_hash"); err != nil {
+			logger.Error("Şifre hash'i güncellenemedi (kullanıcı %d): %v", user.ID, err)
+		}
 	}
 
 	if user.Status != model.StatusActive {
-		return nil, errorx.WrapMsg(errorx.ErrForbidden, "Hesabınız aktif değil. Lütfen yönetici ile iletişime geçin")
+		return nil, "", inactiveAccountErr(user)
+	}
+
+	// Süresi dolan şifre ile oturum açılmaz; kullanıcı şifre sıfırlama ile yeni şifre belirler
+	if user.PasswordExpired() {
+		return nil, "", errorx.WrapMsg(errorx.ErrForbidden, "Şifrenizin süresi doldu. Şifremi unuttum ile yeni bir şifre belirleyin")
 	}
 
+	if user.TwoFactorEnabled && user.PhoneVerified() {
+		challenge, err := jwt.GenerateTwoFactorToken(user.ID, TwoFactorChallengeTTL)
+		if err != nil {
+			return nil, "", errorx.WrapErr(errorx.ErrInternal, err)
+		}
+		if err = s.otpService.Send(ctx, OTPPurposeTwoFactor, user.ID, user.Phone); err != nil {
+			return nil, "", err
+		}
+		return nil, challenge, nil
+	}
+
+	token, err := s.issueTokens(ctx, user)
+	return token, "", err
+}
+
+// VerifyTwoFactor Login'in döndüğü challenge token ve SMS kodu ile girişi tamamlar
+func (s *AuthService) VerifyTwoFactor(ctx context.Context, challenge, code string) (*model.Token, error) {
+	claims, err := jwt.ValidateTwoFactorToken(challenge)
+	if err != nil {
+		return nil, errorx.WrapMsg(errorx.ErrUnauthorized, "Geçersiz veya süresi dolmuş doğrulama isteği")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, errorx.WrapMsg(errorx.ErrNotFound, "Kullanıcı bulunamadı")
+	}
+	if _, err = s.otpService.Verify(ctx, OTPPurposeTwoFactor, user.ID, code); err != nil {
+		return nil, err
+	}
+	if user.Status != model.StatusActive {
+		return nil, inactiveAccountErr(user)
+	}
+
+	return s.issueTokens(ctx, user)
+}
+
+// RequestLoginCode doğrulanmış telefon numarasına giriş kodu gönderir. Numaranın kayıtlı
+// olup olmadığı dışarı sızmasın diye kod gönderilmese de hata dönmez.
+func (s *AuthService) RequestLoginCode(ctx context.Context, phone string) error {
+	user, err := s.userRepo.GetByVerifiedPhone(ctx, phone)
+	if err != nil || user.Status != model.StatusActive || user.TwoFactorEnabled {
+		return nil
+	}
+	return s.otpService.Send(ctx, OTPPurposeLogin, user.ID, user.Phone)
+}
+
+// LoginWithCode doğrulanmış telefona gönderilen SMS kodu ile giriş yapar. İki adımlı
+// doğrulaması açık kullanıcılar şifre ile giriş yapmalıdır; SMS tek başına yeterli değildir.
+func (s *AuthService) LoginWithCode(ctx context.Context, phone, code string) (*model.Token, error) {
+	user, err := s.userRepo.GetByVerifiedPhone(ctx, phone)
+	if err != nil {
+		return nil, errorx.WrapMsg(errorx.ErrUnauthorized, "Kod geçersiz veya süresi dolmuş")
+	}
+	sentTo, err := s.otpService.Verify(ctx, OTPPurposeLogin, user.ID, code)
+	if err != nil {
+		return nil, err
+	}
+	// Kod gönderildikten sonra numara değiştiyse kod eski numaranın sahibine gitmiştir
+	if sentTo != user.Phone {
+		return nil, errorx.WrapMsg(errorx.ErrUnauthorized, "Kod geçersiz veya süresi dolmuş")
+	}
+
+	if user.Status != model.StatusActive {
+		return nil, inactiveAccountErr(user)
+	}
+	if user.PasswordExpired() {
+		return nil, errorx.WrapMsg(errorx.ErrForbidden, "Şifrenizin süresi doldu. Şifremi unuttum ile yeni bir şifre belirleyin")
+	}
+	if user.TwoFactorEnabled {
+		return nil, errorx.WrapMsg(errorx.ErrForbidden, "İki adımlı doğrulama açık. Lütfen şifreniz ile giriş yapın")
+	}
+
+	return s.issueTokens(ctx, user)
+}
+
+// Access/refresh token üretir, token kaydını ve oturumu oluşturur
+func (s *AuthService) issueTokens(ctx context.Context, user *model.User) (*model.Token, error) {
 	// Access token oluştur
 	accessToken, err := jwt.Generate(user)
 	if err != nil {
@@ -73,10 +202,6 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (*model
 		ExpiresAt:    time.Now().Add(time.Duration(24) * time.Hour), // 24 saat
 	}
 
-	if err = s.authRepo.SaveToken(ctx, token); err != nil {
-		return nil, errorx.WrapErr(errorx.ErrInternal, err)
-	}
-
 	// Session oluştur
 	session := &model.Session{
 		UserID:       user.ID,
@@ -86,7 +211,14 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (*model
 		ExpiresAt:    time.Now().Add(time.Duration(168) * time.Hour), // 7 gün
 	}
 
-	if err = s.authRepo.CreateSession(ctx, session); err != nil {
+	// Token ve session birlikte yazılsın
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.authRepo.SaveToken(ctx, token); err != nil {
+			return err
+		}
+		return s.authRepo.CreateSession(ctx, session)
+	})
+	if err != nil {
 		return nil, errorx.WrapErr(errorx.ErrInternal, err)
 	}
 
@@ -139,15 +271,17 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*m
 		ExpiresAt:    time.Now().Add(time.Duration(24) * time.Hour),
 	}
 
-	if err = s.authRepo.SaveToken(ctx, token); err != nil {
-		return nil, errorx.WrapErr(errorx.ErrInternal, err)
-	}
-
 	// Session'ı güncelle
 	session.RefreshToken = newRefreshToken
 	session.ExpiresAt = time.Now().Add(time.Duration(168) * time.Hour)
 
-	if err = s.authRepo.UpdateSession(ctx, session); err != nil {
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.authRepo.SaveToken(ctx, token); err != nil {
+			return err
+		}
+		return s.authRepo.UpdateSession(ctx, session)
+	})
+	if err != nil {
 		return nil, errorx.WrapErr(errorx.ErrInternal, err)
 	}
 
@@ -209,27 +343,55 @@ func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword stri
 	if err != nil {
 		return errorx.WrapMsg(errorx.ErrNotFound, "Kullanıcı bulunamadı")
 	}
+	// Şifre geçmişi kontrolü ve yeni geçmiş için mevcut hash'ler gerekir
+	if err = s.userRepo.LoadCredentials(ctx, user); err != nil {
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	if err = password.PolicyError(user.PasswordViolations(newPassword)); err != nil {
+		return err
+	}
 
 	// Şifreyi güncelle
 	if err = user.SetPassword(newPassword); err != nil {
 		return errorx.WrapErr(errorx.ErrInternal, err)
 	}
 
-	if err = s.userRepo.Update(ctx, user); err != nil {
+	// Şifre güncellemesi ve oturumların sonlandırılması birlikte yapılsın
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		return s.authRepo.DeleteSessionsByUserID(ctx, user.ID)
+	})
+	if err != nil {
 		return errorx.WrapErr(errorx.ErrInternal, err)
 	}
+	revokeUserTokens(ctx, user.ID)
+
+	return nil
+}
+
+// ForceLogout kullanıcının tüm oturumlarını kapatır; refresh token'lar iptal edilir
+// ve mevcut access token'lar süreleri dolmadan reddedilir
+func (s *AuthService) ForceLogout(ctx context.Context, userID int64) error {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return errorx.WrapMsg(errorx.ErrNotFound, "Kullanıcı bulunamadı")
+	}
 
-	// Kullanıcının tüm oturumlarını sonlandır
-	sessions, err := s.authRepo.GetSessionsByUserID(ctx, user.ID)
-	if err == nil {
-		for _, session := range sessions {
-			err = s.authRepo.DeleteSession(ctx, session.ID)
-			if err != nil {
-				return errorx.WrapErr(errorx.ErrInternal, err)
-			}
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.authRepo.RevokeTokensByUserID(ctx, userID); err != nil {
+			return err
 		}
+		return s.authRepo.DeleteSessionsByUserID(ctx, userID)
+	})
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInternal, err)
 	}
 
+	if err = jwt.RevokeUserTokens(ctx, userID); err != nil {
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
 	return nil
 }
 
@@ -265,3 +427,22 @@ func (s *AuthService) CleanupExpiredData(ctx context.Context) error {
 
 	return nil
 }
+
+// Hesap aktif değilse kullanıcıya neden ve (varsa) bitiş zamanı ile döner
+func inactiveAccountErr(user *model.User) error {
+	if !user.Status.Restrictive() {
+		return errorx.WrapMsg(errorx.ErrForbidden, "Hesabınız aktif değil. Lütfen yönetici ile iletişime geçin")
+	}
+
+	msg := "Hesabınız askıya alındı"
+	if user.Status == model.StatusBanned {
+		msg = "Hesabınız yasaklandı"
+	}
+	if user.StatusReason != "" {
+		msg += ". Neden: " + user.StatusReason
+	}
+	if user.StatusExpiresAt != nil {
+		msg += ". Bitiş: " + user.StatusExpiresAt.Format("02.01.2006 15:04")
+	}
+	return errorx.WrapMsg(errorx.ErrForbidden, msg)
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/internal/model"
+	"github.com/Furkanturan8/goftr-template/internal/repository"
+	"github.com/Furkanturan8/goftr-template/pkg/email"
+	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"github.com/Furkanturan8/goftr-template/pkg/jwt"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
+	"strings"
+	"time"
+)
+
+const (
+	emailChangeConfirmTTL = 24 * time.Hour
+	emailChangeRevertTTL  = 7 * 24 * time.Hour
+)
+
+// EmailChangeService kullanıcının e-posta değişikliğini yeni adres onaylanana kadar
+// bekletir. Eski adrese değişikliği geri alabileceği bir link gönderilir.
+type EmailChangeService struct {
+	txManager   repository.ITxManager
+	userService *UserService
+	userRepo    repository.IUserRepository
+	authRepo    repository.IAuthRepository
+	mailer      *email.Email
+	frontendURL string
+}
+
+func NewEmailChangeService(tx repository.ITxManager, us *UserService, u repository.IUserRepository, a repository.IAuthRepository, mailer *email.Email, frontendURL string) *EmailChangeService {
+	return &EmailChangeService{
+		txManager:   tx,
+		userService: us,
+		userRepo:    u,
+		authRepo:    a,
+		mailer:      mailer,
+		frontendURL: strings.TrimRight(frontendURL, "/"),
+	}
+}
+
+// UpdateAndRequest update ile yapılan profil güncellemesini ve e-posta değişikliği talebini
+// tek transaction'da kaydeder. Biri başarısız olursa ikisi de geri alınır ve e-posta gönderilmez.
+func (s *EmailChangeService) UpdateAndRequest(ctx context.Context, user *model.User, newEmail string, update func(ctx context.Context) error) error {
+	return s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := update(ctx); err != nil {
+			return err
+		}
+		return s.Request(ctx, user, newEmail)
+	})
+}
+
+// Request yeni adresi onay bekleyen e-posta olarak kaydeder, yeni adrese onay
+// linki ve eski adrese geri alma linki içeren bildirim gönderir. Transaction
+// içinde çağrılırsa e-postalar commit'ten sonra gönderilir.
+// Önceki bekleyen talep varsa yenisi onun yerine geçer.
+func (s *EmailChangeService) Request(ctx context.Context, user *model.User, newEmail string) error {
+	exists, err := s.userRepo.ExistsByEmail(ctx, newEmail)
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
+	if exists {
+		return errorx.WrapMsg(errorx.ErrDuplicate, "Bu e-posta adresi başka bir kullanıcı tarafından kullanılıyor")
+	}
+
+	user.PendingEmail = newEmail
+	if err = s.userRepo.UpdateFields(ctx, user, "pending_email"); err != nil {
+		return wrapUpdateErr(err)
+	}
+
+	confirmToken, err := jwt.GenerateEmailChangeToken(user.ID, user.Email, newEmail, jwt.EmailChangeConfirm, emailChangeConfirmTTL)
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
+	revertToken, err := jwt.GenerateEmailChangeToken(user.ID, user.Email, newEmail, jwt.EmailChangeRevert, emailChangeRevertTTL)
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	// Geri alınan bir talep için e-posta gitmesin; gönderilemezse kullanıcı talebi tekrarlayabilir
+	userID, firstName, oldEmail := user.ID, user.FirstName, user.Email
+	repository.AfterCommit(ctx, func(context.Context) {
+		body := fmt.Sprintf("Hello %s,\n\nPlease confirm your new email address by clicking the following link:\n\n%s/confirm-email?token=%s\n\nThe link is valid for 24 hours.",
+			firstName, s.frontendURL, confirmToken)
+		if err := s.mailer.Send(newEmail, "Confirm your new email address", body); err != nil {
+			logger.Error("E-posta değişikliği onay e-postası gönderilemedi (kullanıcı %d): %v", userID, err)
+		}
+
+		// Telefonla kayıt olmuş kullanıcının bildirim gidecek eski adresi yoktur
+		if oldEmail == "" {
+			return
+		}
+
+		body = fmt.Sprintf("Hello %s,\n\nA request was made to change the email address of your account to %s.\n"+
+			"If you did not make this request, click the following link to keep your current address and sign out all sessions:\n\n%s/revert-email?token=%s",
+			firstName, newEmail, s.frontendURL, revertToken)
+		if err := s.mailer.Send(oldEmail, "Your email address is being changed", body); err != nil {
+			logger.Error("E-posta değişikliği bildirimi gönderilemedi (kullanıcı %d): %v", userID, err)
+		}
+	})
+
+	return nil
+}
+
+// Confirm onay linkindeki token ile bekleyen e-posta değişikliğini uygular
+func (s *EmailChangeService) Confirm(ctx context.Context, token string) error {
+	claims, err := jwt.ValidateEmailChangeToken(token, jwt.EmailChangeConfirm)
+	if err != nil {
+		return errorx.WrapMsg(errorx.ErrUnauthorized, "Geçersiz veya süresi dolmuş onay linki")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return errorx.WrapMsg(errorx.ErrNotFound, "Kullanıcı bulunamadı")
+	}
+	if user.Email != claims.OldEmail || user.PendingEmail != claims.NewEmail {
+		return errorx.WrapMsg(errorx.ErrInvalidRequest, "Bu e-posta değişikliği talebi artık geçerli değil")
+	}
+
+	// Benzersizlik kontrolü UserService.Update içinde tekrar yapılır
+	user.Email = claims.NewEmail
+	user.PendingEmail = ""
+	return s.userService.Update(ctx, user.ID, user)
+}
+
+// Revert eski adrese gönderilen link ile değişikliği geri alır: talep henüz
+// onaylanmadıysa iptal edilir, onaylandıysa eski adres geri yüklenir.
+// Hesabın ele geçirilmiş olma ihtimaline karşı tüm oturumlar kapatılır.
+func (s *EmailChangeService) Revert(ctx context.Context, token string) error {
+	claims, err := jwt.ValidateEmailChangeToken(token, jwt.EmailChangeRevert)
+	if err != nil {
+		return errorx.WrapMsg(errorx.ErrUnauthorized, "Geçersiz veya süresi dolmuş link")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return errorx.WrapMsg(errorx.ErrNotFound, "Kullanıcı bulunamadı")
+	}
+
+	pending := user.Email == claims.OldEmail && user.PendingEmail == claims.NewEmail
+	if !pending && user.Email != claims.NewEmail {
+		return errorx.WrapMsg(errorx.ErrInvalidRequest, "Bu e-posta değişikliği talebi artık geçerli değil")
+	}
+
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		user.PendingEmail = ""
+		if pending {
+			if err := s.userRepo.UpdateFields(ctx, user, "pending_email"); err != nil {
+				return wrapUpdateErr(err)
+			}
+		} else {
+			user.Email = claims.OldEmail
+			if err := s.userService.Update(ctx, user.ID, user); err != nil {
+				return err
+			}
+		}
+
+		if err := s.authRepo.RevokeTokensByUserID(ctx, user.ID); err != nil {
+			return errorx.WrapErr(errorx.ErrInternal, err)
+		}
+		if err := s.authRepo.DeleteSessionsByUserID(ctx, user.ID); err != nil {
+			return errorx.WrapErr(errorx.ErrInternal, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	revokeUserTokens(ctx, user.ID)
+	return nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/pkg/cache"
+	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"github.com/Furkanturan8/goftr-template/pkg/sms"
+	"math/big"
+	"time"
+)
+
+// SMS ile gönderilen tek kullanımlık kodların amaçları. Bir amaç için
+// gönderilen kod diğerinde kullanılamaz.
+const (
+	OTPPurposeLogin       = "login"
+	OTPPurposeTwoFactor   = "two_factor"
+	OTPPurposeVerifyPhone = "verify_phone"
+)
+
+const (
+	otpLength         = 6
+	otpTTL            = 5 * time.Minute
+	otpMaxAttempts    = 5
+	otpResendInterval = time.Minute
+)
+
+// Redis'te tutulan kod kaydı; kodun kendisi değil hash'i saklanır.
+// Deneme sayısı eşzamanlı denemelerde kaybolmasın diye ayrı bir sayaçta tutulur.
+type otpEntry struct {
+	Hash      string    `json:"hash"`
+	Phone     string    `json:"phone"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// OTPService tek kullanımlık SMS kodlarını üretir, gönderir ve doğrular
+type OTPService struct {
+	sender sms.SMSSender
+	store  cache.Store
+}
+
+func NewOTPService(sender sms.SMSSender, store cache.Store) *OTPService {
+	return &OTPService{sender: sender, store: store}
+}
+
+// Send kullanıcıya yeni bir kod gönderir; önceki kod geçersiz olur.
+// Aynı amaç için otpResendInterval dolmadan tekrar kod gönderilmez.
+func (s *OTPService) Send(ctx context.Context, purpose string, userID int64, phone string) error {
+	cooldownKey := fmt.Sprintf("otp_cooldown:%s:%d", purpose, userID)
+	if exists, _ := s.store.Exists(ctx, cooldownKey); exists {
+		return errorx.WrapMsg(errorx.ErrTooManyRequests, "Yeni kod istemeden önce biraz bekleyin")
+	}
+
+	code, err := generateOTP()
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	entry := otpEntry{
+		Hash:      hashOTP(purpose, userID, code),
+		Phone:     phone,
+		ExpiresAt: time.Now().Add(otpTTL),
+	}
+	if err = s.store.Delete(ctx, otpAttemptsKey(purpose, userID)); err != nil {
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
+	if err = s.store.Set(ctx, otpKey(purpose, userID), entry, otpTTL); err != nil {
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
+	if err = s.store.Set(ctx, cooldownKey, true, otpResendInterval); err != nil {
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	message := fmt.Sprintf("Your verification code is %s. It expires in %d minutes.", code, int(otpTTL.Minutes()))
+	if err = s.sender.Send(ctx, phone, message); err != nil {
+		_ = s.store.Delete(ctx, otpKey(purpose, userID))
+		return errorx.Wrap(errorx.ErrInternal, err, "SMS gönderilemedi")
+	}
+	return nil
+}
+
+// Verify kodu doğrular ve kodun gönderildiği telefonu döner. Kod tek kullanımlıktır;
+// otpMaxAttempts hatalı denemeden sonra silinir ve yeni kod istenmesi gerekir.
+func (s *OTPService) Verify(ctx context.Context, purpose string, userID int64, code string) (string, error) {
+	key := otpKey(purpose, userID)
+
+	var entry otpEntry
+	if err := s.store.Get(ctx, key, &entry); err != nil || time.Now().After(entry.ExpiresAt) {
+		return "", errorx.WrapMsg(errorx.ErrUnauthorized, "Kod geçersiz veya süresi dolmuş")
+	}
+
+	// Deneme, kod karşılaştırılmadan önce sayılır; paralel istekler de otpMaxAttempts'ı aşamaz
+	attempts, err := s.store.Incr(ctx, otpAttemptsKey(purpose, userID), time.Until(entry.ExpiresAt))
+	if err != nil {
+		return "", errorx.WrapErr(errorx.ErrInternal, err)
+	}
+	if attempts > otpMaxAttempts {
+		_ = s.store.Delete(ctx, key)
+		return "", errorx.WrapMsg(errorx.ErrUnauthorized, "Kod geçersiz veya süresi dolmuş")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashOTP(purpose, userID, code)), []byte(entry.Hash)) != 1 {
+		if attempts == otpMaxAttempts {
+			_ = s.store.Delete(ctx, key)
+		}
+		return "", errorx.WrapMsg(errorx.ErrUnauthorized, "Kod geçersiz veya süresi dolmuş")
+	}
+
+	// Aynı kodla eşzamanlı gelen isteklerden sadece biri kodu kullanabilir
+	claimed, err := s.store.SetNX(ctx, key+":used:"+entry.Hash, true, time.Until(entry.ExpiresAt))
+	if err != nil {
+		return "", errorx.WrapErr(errorx.ErrInternal, err)
+	}
+	if !claimed {
+		return "", errorx.WrapMsg(errorx.ErrUnauthorized, "Kod geçersiz veya süresi dolmuş")
+	}
+
+	_ = s.store.Delete(ctx, key)
+	return entry.Phone, nil
+}
+
+func otpKey(purpose string, userID int64) string {
+	return fmt.Sprintf("otp:%s:%d", purpose, userID)
+}
+
+func otpAttemptsKey(purpose string, userID int64) string {
+	return fmt.Sprintf("otp_attempts:%s:%d", purpose, userID)
+}
+
+func hashOTP(purpose string, userID int64, code string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%s", purpose, userID, code)))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateOTP() (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(otpLength), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", otpLength, n), nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"github.com/Furkanturan8/goftr-template/internal/model"
+	"github.com/Furkanturan8/goftr-template/internal/repository"
+	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"time"
+)
+
+// PhoneService kullanıcının kendi telefon numarasını değiştirmesini, SMS koduyla
+// doğrulamasını ve SMS ile iki adımlı doğrulamayı açıp kapatmasını yönetir
+type PhoneService struct {
+	userRepo   repository.IUserRepository
+	otpService *OTPService
+}
+
+func NewPhoneService(u repository.IUserRepository, otp *OTPService) *PhoneService {
+	return &PhoneService{
+		userRepo:   u,
+		otpService: otp,
+	}
+}
+
+// ChangePhone şifre doğrulaması ile telefonu değiştirir ve numaraya doğrulama kodu
+// gönderir. Aynı (doğrulanmamış) numara tekrar verilirse sadece kod yeniden gönderilir.
+// Numara değişirse doğrulanana kadar iki adımlı doğrulama kapatılır.
+func (s *PhoneService) ChangePhone(ctx context.Context, userID int64, phone, password string) (*model.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errorx.WrapMsg(errorx.ErrNotFound, "Kullanıcı bulunamadı")
+	}
+	if err = s.userRepo.LoadCredentials(ctx, user); err != nil {
+		return nil, errorx.WrapErr(errorx.ErrInternal, err)
+	}
+	if !user.CheckPassword(password) {
+		return nil, errorx.WrapMsg(errorx.ErrInvalidCredentials, "Girdiğiniz şifre yanlış")
+	}
+
+	if phone == user.Phone {
+		if user.PhoneVerified() {
+			return nil, errorx.WrapMsg(errorx.ErrInvalidRequest, "Bu telefon numarası zaten doğrulanmış")
+		}
+	} else {
+		exists, err := s.userRepo.ExistsByPhone(ctx, phone)
+		if err != nil {
+			return nil, errorx.WrapErr(errorx.ErrInternal, err)
+		}
+		if exists {
+			return nil, errorx.WrapMsg(errorx.ErrDuplicate, "Bu telefon numarası başka bir kullanıcı tarafından kullanılıyor")
+		}
+
+		user.Phone = phone
+		user.PhoneVerifiedAt = nil
+		user.TwoFactorEnabled = false
+		if err = s.userRepo.UpdateFields(ctx, user, "phone", "phone_verified_at", "two_factor_enabled"); err != nil {
+			return nil, wrapUpdateErr(err)
+		}
+	}
+
+	if err = s.otpService.Send(ctx, OTPPurposeVerifyPhone, user.ID, user.Phone); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// VerifyPhone SMS ile gönderilen kodu doğrular ve telefonu doğrulanmış olarak işaretler
+func (s *PhoneService) VerifyPhone(ctx context.Context, userID int64, code string) (*model.User, error) {
+	sentTo, err := s.otpService.Verify(ctx, OTPPurposeVerifyPhone, userID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errorx.WrapMsg(errorx.ErrNotFound, "Kullanıcı bulunamadı")
+	}
+	// Kod gönderildikten sonra numara değişmiş olabilir
+	if sentTo != user.Phone {
+		return nil, errorx.WrapMsg(errorx.ErrInvalidRequest, "Kod güncel telefon numaranıza gönderilmemiş")
+	}
+
+	now := time.Now()
+	user.PhoneVerifiedAt = &now
+	if err = s.userRepo.UpdateFields(ctx, user, "phone_verified_at"); err != nil {
+		return nil, wrapUpdateErr(err)
+	}
+	return user, nil
+}
+
+// SetTwoFactor şifre doğrulaması ile SMS ile iki adımlı doğrulamayı açar veya kapatır
+func (s *PhoneService) SetTwoFactor(ctx context.Context, userID int64, enabled bool, password string) (*model.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errorx.WrapMsg(errorx.ErrNotFound, "Kullanıcı bulunamadı")
+	}
+	if err = s.userRepo.LoadCredentials(ctx, user); err != nil {
+		return nil, errorx.WrapErr(errorx.ErrInternal, err)
+	}
+	if !user.CheckPassword(password) {
+		return nil, errorx.WrapMsg(errorx.ErrInvalidCredentials, "Girdiğiniz şifre yanlış")
+	}
+	if enabled && !user.PhoneVerified() {
+		return nil, errorx.WrapMsg(errorx.ErrInvalidRequest, "İki adımlı doğrulama için önce telefonunuzu doğrulayın")
+	}
+
+	user.TwoFactorEnabled = enabled
+	if err = s.userRepo.UpdateFields(ctx, user, "two_factor_enabled"); err != nil {
+		return nil, wrapUpdateErr(err)
+	}
+	return user, nil
+}
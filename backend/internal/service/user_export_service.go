@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/internal/model"
+	"github.com/Furkanturan8/goftr-template/internal/repository"
+	"github.com/Furkanturan8/goftr-template/pkg/cache"
+	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"github.com/Furkanturan8/goftr-template/pkg/export"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
+	"github.com/Furkanturan8/goftr-template/pkg/query"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Arka plan export job durumları
+const (
+	ExportStatusPending   = "pending"
+	ExportStatusRunning   = "running"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+)
+
+const (
+	exportCacheKeyPrefix = "export:"
+	exportTimeout        = 30 * time.Minute
+)
+
+// Dışa aktarılabilen kullanıcı kolonları. password_hash bilerek listede yok;
+// filtre ve sıralama da sadece bu kolonlarla yapılabilir.
+var userExportColumns = map[string]func(u *model.User) string{
+	"id":         func(u *model.User) string { return strconv.FormatInt(u.ID, 10) },
+	"email":      func(u *model.User) string { return u.Email },
+	"phone":      func(u *model.User) string { return u.Phone },
+	"username":   func(u *model.User) string { return u.Username },
+	"first_name": func(u *model.User) string { return u.FirstName },
+	"last_name":  func(u *model.User) string { return u.LastName },
+	"role":       func(u *model.User) string { return string(u.Role) },
+	"status":     func(u *model.User) string { return string(u.Status) },
+	"last_login": func(u *model.User) string { return formatExportTime(u.LastLogin) },
+	"created_at": func(u *model.User) string { return formatExportTime(u.CreatedAt) },
+	"updated_at": func(u *model.User) string { return formatExportTime(u.UpdatedAt) },
+}
+
+// Kolon seçilmezse kullanılan varsayılan sıra
+var DefaultUserExportColumns = []string{"id", "email", "first_name", "last_name", "role", "status", "last_login", "created_at", "updated_at"}
+
+type ExportRequest struct {
+	Format  string
+	Columns []string
+	Params  *query.Params
+}
+
+type ExportJob struct {
+	ID          string     `json:"id"`
+	OwnerID     int64      `json:"owner_id"`
+	Format      string     `json:"format"`
+	Status      string     `json:"status"`
+	Rows        int64      `json:"rows"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+}
+
+type UserExportService struct {
+	userRepo       repository.IUserRepository
+	store          cache.Store // Arka plan job durumları
+	dir            string
+	asyncThreshold int
+	ttl            time.Duration
+}
+
+func NewUserExportService(u repository.IUserRepository, store cache.Store, dir string, asyncThreshold int, ttl time.Duration) *UserExportService {
+	return &UserExportService{
+		userRepo:       u,
+		store:          store,
+		dir:            dir,
+		asyncThreshold: asyncThreshold,
+		ttl:            ttl,
+	}
+}
+
+// Validate formatı ve kolonları kontrol eder, kolon verilmemişse varsayılanları atar.
+// Export tüm kayıtları döndüğü için sayfalama kaldırılır.
+func (s *UserExportService) Validate(req *ExportRequest) error {
+	if !export.Supported(req.Format) {
+		return errorx.WrapMsg(errorx.ErrInvalidRequest, "Format csv, ndjson veya xlsx olmalı")
+	}
+
+	if len(req.Columns) == 0 {
+		req.Columns = DefaultUserExportColumns
+	}
+	for _, column := range req.Columns {
+		if _, ok := userExportColumns[column]; !ok {
+			return errorx.WrapMsg(errorx.ErrInvalidRequest, "Geçersiz kolon: "+column)
+		}
+	}
+
+	if req.Params == nil {
+		req.Params = &query.Params{}
+	}
+	for _, f := range req.Params.Filters {
+		if _, ok := userExportColumns[f.Field]; !ok {
+			return errorx.WrapMsg(errorx.ErrInvalidRequest, "Bu alana göre filtrelenemez: "+f.Field)
+		}
+	}
+	for _, sort := range req.Params.Sort {
+		if _, ok := userExportColumns[sort.Field]; !ok {
+			return errorx.WrapMsg(errorx.ErrInvalidRequest, "Bu alana göre sıralanamaz: "+sort.Field)
+		}
+	}
+	req.Params.Pagination = query.Pagination{}
+
+	return nil
+}
+
+// ShouldRunAsync kayıt sayısı eşiği aşıyorsa export'un arka planda çalışması gerektiğini döner
+func (s *UserExportService) ShouldRunAsync(ctx context.Context, req *ExportRequest) (bool, error) {
+	count, err := s.userRepo.Count(ctx, req.Params)
+	if err != nil {
+		return false, errorx.WrapErr(errorx.ErrInternal, err)
+	}
+	return count > s.asyncThreshold, nil
+}
+
+// Write kullanıcıları veritabanından cursor ile okuyarak w'ye yazar ve yazılan satır sayısını döner
+func (s *UserExportService) Write(ctx context.Context, req *ExportRequest, w io.Writer) (int64, error) {
+	writer, err := export.NewWriter(req.Format, w)
+	if err != nil {
+		return 0, err
+	}
+	if err = writer.WriteHeader(req.Columns); err != nil {
+		return 0, err
+	}
+
+	var n int64
+	values := make([]string, len(req.Columns))
+	err = s.userRepo.Stream(ctx, req.Params, req.Columns, func(u *model.User) error {
+		for i, column := range req.Columns {
+			values[i] = userExportColumns[column](u)
+		}
+		n++
+		return writer.WriteRow(values)
+	})
+	if err != nil {
+		return n, err
+	}
+
+	return n, writer.Close()
+}
+
+// Start export'u arka planda başlatır. Job durumu cache'te tutulur, dosya
+// tamamlandığında export dizinine yazılır ve ttl süresince indirilebilir.
+func (s *UserExportService) Start(ctx context.Context, ownerID int64, req *ExportRequest) (*ExportJob, error) {
+	id, err := newExportID()
+	if err != nil {
+		return nil, errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	job := &ExportJob{
+		ID:        id,
+		OwnerID:   ownerID,
+		Format:    req.Format,
+		Status:    ExportStatusPending,
+		CreatedAt: time.Now(),
+	}
+	if err = s.saveJob(ctx, job); err != nil {
+		return nil, errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	go s.run(*job, req)
+
+	return job, nil
+}
+
+func (s *UserExportService) run(j ExportJob, req *ExportRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+
+	job := &j
+	job.Status = ExportStatusRunning
+	_ = s.saveJob(ctx, job)
+
+	rows, err := s.writeFile(ctx, job, req)
+	now := time.Now()
+	job.CompletedAt = &now
+	job.Rows = rows
+	if err != nil {
+		logger.Error("Kullanıcı export hatası (%s): %v", job.ID, err)
+		job.Status = ExportStatusFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = ExportStatusCompleted
+	}
+
+	if err = s.saveJob(ctx, job); err != nil {
+		logger.Error("Export job durumu kaydedilemedi (%s): %v", job.ID, err)
+	}
+}
+
+// Dosyayı önce geçici isimle yazar, tamamlanınca yerine taşır
+func (s *UserExportService) writeFile(ctx context.Context, job *ExportJob, req *ExportRequest) (int64, error) {
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return 0, err
+	}
+
+	path := s.FilePath(job)
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := s.Write(ctx, req, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path + ".tmp")
+		return rows, err
+	}
+
+	return rows, os.Rename(path+".tmp", path)
+}
+
+// GetJob export job'unu getirir. Job'u sadece başlatan kullanıcı görebilir.
+func (s *UserExportService) GetJob(ctx context.Context, ownerID int64, id string) (*ExportJob, error) {
+	var job ExportJob
+	if err := s.store.Get(ctx, exportCacheKeyPrefix+id, &job); err != nil || job.OwnerID != ownerID {
+		return nil, errorx.WrapMsg(errorx.ErrNotFound, "Export bulunamadı veya süresi doldu")
+	}
+	return &job, nil
+}
+
+// FilePath tamamlanmış export dosyasının yolunu döner
+func (s *UserExportService) FilePath(job *ExportJob) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s.%s", job.ID, job.Format))
+}
+
+// CleanupExpired süresi dolmuş export dosyalarını siler ve silinen dosya sayısını döner
+func (s *UserExportService) CleanupExpired(ctx context.Context) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	deadline := time.Now().Add(-s.ttl)
+	removed := 0
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return removed, ctx.Err()
+		}
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || info.ModTime().After(deadline) {
+			continue
+		}
+		if err = os.Remove(filepath.Join(s.dir, entry.Name())); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (s *UserExportService) saveJob(ctx context.Context, job *ExportJob) error {
+	return s.store.Set(ctx, exportCacheKeyPrefix+job.ID, job, s.ttl)
+}
+
+func newExportID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
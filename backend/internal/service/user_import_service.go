@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/internal/model"
+	"github.com/Furkanturan8/goftr-template/internal/repository"
+	"github.com/Furkanturan8/goftr-template/pkg/email"
+	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"github.com/Furkanturan8/goftr-template/pkg/jwt"
+	"strings"
+)
+
+const defaultImportBatchSize = 100
+
+// İçe aktarma raporundaki satır durumları
+const (
+	ImportStatusValid     = "valid"     // dry-run: oluşturulabilir
+	ImportStatusCreated   = "created"   // oluşturuldu
+	ImportStatusInvalid   = "invalid"   // doğrulama hatası
+	ImportStatusDuplicate = "duplicate" // e-posta dosyada veya veritabanında mevcut
+	ImportStatusFailed    = "failed"    // veritabanına yazılamadı
+)
+
+type ImportOptions struct {
+	DryRun      bool
+	BatchSize   int
+	SendInvites bool
+}
+
+// ImportCandidate doğrulanmış (veya doğrulama hatası almış) tek bir satırdır
+type ImportCandidate struct {
+	Line   int
+	User   model.User
+	Errors []string
+}
+
+type ImportRowResult struct {
+	Line    int      `json:"line"`
+	Email   string   `json:"email"`
+	Status  string   `json:"status"`
+	Errors  []string `json:"errors,omitempty"`
+	Invited bool     `json:"invited,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun     bool              `json:"dry_run"`
+	Total      int               `json:"total"`
+	Valid      int               `json:"valid"`
+	Created    int               `json:"created"`
+	Invalid    int               `json:"invalid"`
+	Duplicates int               `json:"duplicates"`
+	Failed     int               `json:"failed"`
+	Invited    int               `json:"invited"`
+	Rows       []ImportRowResult `json:"rows"`
+}
+
+type UserImportService struct {
+	txManager repository.ITxManager
+	userRepo  repository.IUserRepository
+	mailer    *email.Email
+	inviteURL string
+}
+
+func NewUserImportService(tx repository.ITxManager, u repository.IUserRepository, mailer *email.Email, inviteURL string) *UserImportService {
+	return &UserImportService{
+		txManager: tx,
+		userRepo:  u,
+		mailer:    mailer,
+		inviteURL: inviteURL,
+	}
+}
+
+// Import satırları kontrol eder ve geçerli olanları batch'ler halinde oluşturur.
+// Her batch tek transaction'dır; batch içindeki her satır savepoint ile yazılır,
+// böylece hatalı bir satır sadece kendisini geri alır. DryRun modunda
+// veritabanına yazılmaz, sadece rapor döner.
+func (s *UserImportService) Import(ctx context.Context, candidates []ImportCandidate, opts ImportOptions) (*ImportReport, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultImportBatchSize
+	}
+
+	report := &ImportReport{
+		DryRun: opts.DryRun,
+		Total:  len(candidates),
+		Rows:   make([]ImportRowResult, len(candidates)),
+	}
+
+	seen := make(map[string]int, len(candidates))
+	seenPhones := make(map[string]int)
+	seenUsernames := make(map[string]int)
+	var pending []int
+	for i, c := range candidates {
+		row := &report.Rows[i]
+		row.Line = c.Line
+		row.Email = c.User.Email
+
+		if len(c.Errors) > 0 {
+			row.Status = ImportStatusInvalid
+			row.Errors = c.Errors
+			continue
+		}
+
+		if line, ok := seen[c.User.Email]; ok {
+			row.Status = ImportStatusDuplicate
+			row.Errors = []string{fmt.Sprintf("e-posta dosyada tekrar ediyor (satır %d)", line)}
+			continue
+		}
+		seen[c.User.Email] = c.Line
+
+		exists, err := s.userRepo.ExistsByEmail(ctx, c.User.Email)
+		if err != nil {
+			return nil, errorx.WrapErr(errorx.ErrInternal, err)
+		}
+		if exists {
+			row.Status = ImportStatusDuplicate
+			row.Errors = []string{"e-posta adresi zaten kullanımda"}
+			continue
+		}
+
+		if phone := c.User.Phone; phone != "" {
+			if line, ok := seenPhones[phone]; ok {
+				row.Status = ImportStatusDuplicate
+				row.Errors = []string{fmt.Sprintf("telefon dosyada tekrar ediyor (satır %d)", line)}
+				continue
+			}
+			seenPhones[phone] = c.Line
+
+			exists, err = s.userRepo.ExistsByPhone(ctx, phone)
+			if err != nil {
+				return nil, errorx.WrapErr(errorx.ErrInternal, err)
+			}
+			if exists {
+				row.Status = ImportStatusDuplicate
+				row.Errors = []string{"telefon numarası zaten kullanımda"}
+				continue
+			}
+		}
+
+		if username := strings.ToLower(c.User.Username); username != "" {
+			if line, ok := seenUsernames[username]; ok {
+				row.Status = ImportStatusDuplicate
+				row.Errors = []string{fmt.Sprintf("kullanıcı adı dosyada tekrar ediyor (satır %d)", line)}
+				continue
+			}
+			seenUsernames[username] = c.Line
+
+			exists, err = s.userRepo.ExistsByUsername(ctx, username)
+			if err != nil {
+				return nil, errorx.WrapErr(errorx.ErrInternal, err)
+			}
+			if exists {
+				row.Status = ImportStatusDuplicate
+				row.Errors = []string{"kullanıcı adı zaten kullanımda"}
+				continue
+			}
+		}
+
+		row.Status = ImportStatusValid
+		pending = append(pending, i)
+	}
+
+	if !opts.DryRun {
+		for start := 0; start < len(pending); start += opts.BatchSize {
+			end := start + opts.BatchSize
+			if end > len(pending) {
+				end = len(pending)
+			}
+			if err := s.createBatch(ctx, candidates, report, pending[start:end], opts.SendInvites); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	report.count()
+	return report, nil
+}
+
+func (s *UserImportService) createBatch(ctx context.Context, candidates []ImportCandidate, report *ImportReport, batch []int, invite bool) error {
+	created := make(map[int]*model.User, len(batch))
+
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		for _, i := range batch {
+			user := candidates[i].User
+			err := s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+				return s.userRepo.Create(ctx, &user)
+			})
+			if err != nil {
+				report.Rows[i].Status = ImportStatusFailed
+				report.Rows[i].Errors = []string{err.Error()}
+				continue
+			}
+			report.Rows[i].Status = ImportStatusCreated
+			created[i] = &user
+		}
+		return nil
+	})
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	// Davetler transaction commit edildikten sonra gönderilir
+	if !invite || s.mailer == nil {
+		return nil
+	}
+	for i, user := range created {
+		if err = s.sendInvite(user); err != nil {
+			report.Rows[i].Errors = append(report.Rows[i].Errors, "davet e-postası gönderilemedi: "+err.Error())
+			continue
+		}
+		report.Rows[i].Invited = true
+	}
+	return nil
+}
+
+// Kullanıcıya şifresini belirleyebileceği şifre sıfırlama linkini gönderir
+func (s *UserImportService) sendInvite(user *model.User) error {
+	token, err := jwt.GeneratePasswordResetToken(user)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", strings.TrimRight(s.inviteURL, "/"), token)
+	body := fmt.Sprintf("Hello %s,\n\nAn account has been created for you. Click the following link to set your password:\n\n%s", user.FirstName, link)
+	return s.mailer.Send(user.Email, "You have been invited", body)
+}
+
+func (r *ImportReport) count() {
+	for _, row := range r.Rows {
+		switch row.Status {
+		case ImportStatusValid:
+			r.Valid++
+		case ImportStatusCreated:
+			r.Valid++
+			r.Created++
+		case ImportStatusInvalid:
+			r.Invalid++
+		case ImportStatusDuplicate:
+			r.Duplicates++
+		case ImportStatusFailed:
+			r.Valid++
+			r.Failed++
+		}
+		if row.Invited {
+			r.Invited++
+		}
+	}
+}
@@ -2,40 +2,68 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"github.com/Furkanturan8/goftr-template/internal/model"
 	"github.com/Furkanturan8/goftr-template/internal/repository"
 	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"github.com/Furkanturan8/goftr-template/pkg/jwt"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
+	"github.com/Furkanturan8/goftr-template/pkg/query"
+	"strings"
+	"time"
+)
+
+// Kullanıcının kendi kullanıcı adını iki değişiklik arasında beklemesi gereken süre
+const usernameChangeCooldown = 30 * 24 * time.Hour
+
+// Silinmiş kullanıcılar için saklama süresi dolduğunda uygulanacak işlem
+const (
+	RetentionModePurge     = "purge"
+	RetentionModeAnonymize = "anonymize"
 )
 
 type UserService struct {
-	userRepo repository.IUserRepository
+	txManager repository.ITxManager
+	userRepo  repository.IUserRepository
+	authRepo  repository.IAuthRepository
 }
 
-func NewUserService(u repository.IUserRepository) *UserService {
+func NewUserService(tx repository.ITxManager, u repository.IUserRepository, a repository.IAuthRepository) *UserService {
 	return &UserService{
-		userRepo: u,
+		txManager: tx,
+		userRepo:  u,
+		authRepo:  a,
 	}
 }
 
 func (s *UserService) Create(ctx context.Context, user model.User) error {
 	// Email kontrolü
-	exists, err := s.userRepo.ExistsByEmail(ctx, user.Email)
-	if err != nil {
-		return errorx.WrapErr(errorx.ErrInternal, err)
+	if user.Email != "" {
+		exists, err := s.userRepo.ExistsByEmail(ctx, user.Email)
+		if err != nil {
+			return errorx.WrapErr(errorx.ErrInternal, err)
+		}
+		if exists {
+			return errorx.WrapMsg(errorx.ErrDuplicate, "Bu e-posta adresi zaten kullanımda")
+		}
 	}
-	if exists {
-		return errorx.WrapMsg(errorx.ErrDuplicate, "Bu e-posta adresi zaten kullanımda")
+	if err := s.checkPhoneAvailable(ctx, user.Phone); err != nil {
+		return err
+	}
+	if err := checkUsernameAvailable(ctx, s.userRepo, user.Username); err != nil {
+		return err
 	}
 
-	if err = s.userRepo.Create(ctx, &user); err != nil {
+	if err := s.userRepo.Create(ctx, &user); err != nil {
 		return errorx.WrapErr(errorx.ErrInternal, err)
 	}
 
 	return nil
 }
 
-func (s *UserService) List(ctx context.Context) ([]model.User, error) {
-	users, err := s.userRepo.List(ctx)
+func (s *UserService) List(ctx context.Context, params *query.Params) ([]model.User, error) {
+	users, err := s.userRepo.List(ctx, params)
 	if err != nil {
 		return nil, errorx.WrapErr(errorx.ErrInternal, err)
 	}
@@ -43,6 +71,14 @@ func (s *UserService) List(ctx context.Context) ([]model.User, error) {
 	return users, nil
 }
 
+// LoadCredentials şifre kontrolünden önce kullanıcının şifre alanlarını veritabanından yükler
+func (s *UserService) LoadCredentials(ctx context.Context, user *model.User) error {
+	if err := s.userRepo.LoadCredentials(ctx, user); err != nil {
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
+	return nil
+}
+
 func (s *UserService) GetByID(ctx context.Context, id int64) (*model.User, error) {
 	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
@@ -52,11 +88,14 @@ func (s *UserService) GetByID(ctx context.Context, id int64) (*model.User, error
 	return user, nil
 }
 
-func (s *UserService) Update(ctx context.Context, id int64, updatedUser model.User) error {
+func (s *UserService) Update(ctx context.Context, id int64, updatedUser *model.User) error {
 	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return errorx.WrapMsg(errorx.ErrNotFound, "Güncellenecek kullanıcı bulunamadı")
 	}
+	if err = checkStatusUnchanged(user.Status, updatedUser.Status); err != nil {
+		return err
+	}
 
 	if updatedUser.Email != "" {
 		// Email değişiyorsa, yeni email'in başka bir kullanıcıda olmadığından emin ol
@@ -71,23 +110,326 @@ func (s *UserService) Update(ctx context.Context, id int64, updatedUser model.Us
 		}
 	}
 
-	if err = s.userRepo.Update(ctx, &updatedUser); err != nil {
-		return errorx.WrapErr(errorx.ErrInternal, err)
+	// Telefon değişiyorsa benzersiz olmalı ve yeni numara doğrulanana kadar doğrulanmamış sayılır
+	if updatedUser.Phone != user.Phone {
+		if err = s.checkPhoneAvailable(ctx, updatedUser.Phone); err != nil {
+			return err
+		}
+		// Doğrulanmamış telefona kod gönderilmediği için 2FA da kapatılır (PhoneService.ChangePhone gibi)
+		updatedUser.PhoneVerifiedAt = nil
+		updatedUser.TwoFactorEnabled = false
+	}
+
+	// Sadece harf büyüklüğü değişiyorsa kayıt kendisiyle çakışmasın
+	if updatedUser.Username != "" && !strings.EqualFold(updatedUser.Username, user.Username) {
+		if err = checkUsernameAvailable(ctx, s.userRepo, updatedUser.Username); err != nil {
+			return err
+		}
+	} else if err = validateUsername(updatedUser.Username); err != nil {
+		return err
+	}
+
+	return s.save(ctx, id, user, updatedUser, func(ctx context.Context) error {
+		return s.userRepo.Update(ctx, updatedUser)
+	})
+}
+
+// Patch sadece verilen kolonları günceller. user, mevcut kaydın patch uygulanmış hali olmalı.
+func (s *UserService) Patch(ctx context.Context, id int64, user *model.User, columns []string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+
+	current, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return errorx.WrapMsg(errorx.ErrNotFound, "Güncellenecek kullanıcı bulunamadı")
+	}
+	if err = checkStatusUnchanged(current.Status, user.Status); err != nil {
+		return err
+	}
+
+	if user.Email != current.Email {
+		exists, err := s.userRepo.ExistsByEmail(ctx, user.Email)
+		if err != nil {
+			return errorx.WrapErr(errorx.ErrInternal, err)
+		}
+		if exists {
+			return errorx.WrapMsg(errorx.ErrDuplicate, "Bu e-posta adresi başka bir kullanıcı tarafından kullanılıyor")
+		}
+	}
+
+	user.ID = id
+	return s.save(ctx, id, current, user, func(ctx context.Context) error {
+		return s.userRepo.UpdateFields(ctx, user, columns...)
+	})
+}
+
+// Değişikliği kaydeder. Şifre değiştiyse refresh token'lar ve oturumlar aynı transaction'da
+// sonlandırılır; yoksa eski refresh token ile yeni access token alınmaya devam edilebilir.
+func (s *UserService) save(ctx context.Context, id int64, before, after *model.User, write func(ctx context.Context) error) error {
+	// GetByID şifre alanlarını döndürmez; şifre verildiyse değişip değişmediği veritabanındaki hash ile karşılaştırılır
+	passwordChanged := false
+	if after.Password != "" {
+		current := &model.User{BaseModel: model.BaseModel{ID: id}}
+		if err := s.userRepo.LoadCredentials(ctx, current); err != nil {
+			return errorx.WrapErr(errorx.ErrInternal, err)
+		}
+		passwordChanged = after.Password != current.Password
+	}
+
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := write(ctx); err != nil {
+			return err
+		}
+		if !passwordChanged {
+			return nil
+		}
+		if err := s.authRepo.RevokeTokensByUserID(ctx, id); err != nil {
+			return err
+		}
+		return s.authRepo.DeleteSessionsByUserID(ctx, id)
+	})
+	if err != nil {
+		return wrapUpdateErr(err)
 	}
 
+	if passwordChanged || claimsChanged(before, after) {
+		revokeUserTokens(ctx, id)
+	}
 	return nil
 }
 
-func (s *UserService) Delete(ctx context.Context, id int64) error {
+// Delete kullanıcıyı siler. version 0 değilse sadece o versiyondaki kayıt silinir.
+func (s *UserService) Delete(ctx context.Context, id int64, version int64) error {
 	// Önce kullanıcının var olup olmadığını kontrol et
 	_, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return errorx.WrapMsg(errorx.ErrNotFound, "Silinecek kullanıcı bulunamadı")
 	}
 
-	if err = s.userRepo.Delete(ctx, id); err != nil {
+	if version > 0 {
+		err = s.userRepo.DeleteWithVersion(ctx, id, version)
+	} else {
+		err = s.userRepo.Delete(ctx, id)
+	}
+	if err != nil {
+		return wrapUpdateErr(err)
+	}
+
+	return nil
+}
+
+// ChangeUsername kullanıcının kendi kullanıcı adını belirler veya değiştirir.
+// İlk belirleme hariç iki değişiklik arasında usernameChangeCooldown kadar beklenmelidir.
+func (s *UserService) ChangeUsername(ctx context.Context, userID int64, username string) (*model.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errorx.WrapMsg(errorx.ErrNotFound, "Kullanıcı bulunamadı")
+	}
+	if username == user.Username {
+		return user, nil
+	}
+
+	if user.Username != "" && user.UsernameChangedAt != nil {
+		if next := user.UsernameChangedAt.Add(usernameChangeCooldown); time.Now().Before(next) {
+			return nil, errorx.WrapMsg(errorx.ErrValidation, "Kullanıcı adınızı "+next.Format("02.01.2006")+" tarihinden sonra değiştirebilirsiniz")
+		}
+	}
+
+	if strings.EqualFold(username, user.Username) {
+		err = validateUsername(username)
+	} else {
+		err = checkUsernameAvailable(ctx, s.userRepo, username)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.Username = username
+	user.UsernameChangedAt = &now
+	if err = s.userRepo.UpdateFields(ctx, user, "username", "username_changed_at"); err != nil {
+		return nil, wrapUpdateErr(err)
+	}
+	return user, nil
+}
+
+// UsernameAvailability kullanıcı adının alınıp alınamayacağını döner; alınamıyorsa nedenini de döner
+func (s *UserService) UsernameAvailability(ctx context.Context, username string) (bool, string, error) {
+	if err := model.ValidateUsername(username); err != nil {
+		return false, err.Error(), nil
+	}
+	exists, err := s.userRepo.ExistsByUsername(ctx, username)
+	if err != nil {
+		return false, "", errorx.WrapErr(errorx.ErrInternal, err)
+	}
+	if exists {
+		return false, "kullanımda", nil
+	}
+	return true, "", nil
+}
+
+// Kullanıcı adı kurallara uygun olmalı ve başka bir (silinmemiş) kullanıcıda olmamalı
+func checkUsernameAvailable(ctx context.Context, repo repository.IUserRepository, username string) error {
+	if username == "" {
+		return nil
+	}
+	if err := validateUsername(username); err != nil {
+		return err
+	}
+	exists, err := repo.ExistsByUsername(ctx, username)
+	if err != nil {
 		return errorx.WrapErr(errorx.ErrInternal, err)
 	}
+	if exists {
+		return errorx.WrapMsg(errorx.ErrDuplicate, "Bu kullanıcı adı zaten kullanımda")
+	}
+	return nil
+}
+
+func validateUsername(username string) error {
+	if username == "" {
+		return nil
+	}
+	if err := model.ValidateUsername(username); err != nil {
+		return errorx.WrapMsg(errorx.ErrValidation, err.Error())
+	}
+	return nil
+}
+
+// Telefon numarası E.164 formatında olmalı ve başka bir (silinmemiş) kullanıcıda olmamalı
+func (s *UserService) checkPhoneAvailable(ctx context.Context, phone string) error {
+	if phone == "" {
+		return nil
+	}
+	if err := model.ValidatePhone(phone); err != nil {
+		return errorx.WrapMsg(errorx.ErrValidation, err.Error())
+	}
+	exists, err := s.userRepo.ExistsByPhone(ctx, phone)
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
+	if exists {
+		return errorx.WrapMsg(errorx.ErrDuplicate, "Bu telefon numarası başka bir kullanıcı tarafından kullanılıyor")
+	}
+	return nil
+}
 
+// Durum değişiklikleri neden, geçmiş ve bildirim gerektirdiği için
+// sadece UserStatusService üzerinden yapılabilir
+func checkStatusUnchanged(current, updated model.Status) error {
+	if updated != "" && updated != current {
+		return errorx.WrapMsg(errorx.ErrValidation, "Durum değişikliği için POST /users/:id/status kullanılmalı")
+	}
 	return nil
 }
+
+// Token'a gömülü bilgiler (rol, durum) değiştiyse eski token'lar geçersiz olmalı
+func claimsChanged(before, after *model.User) bool {
+	return before.Role != after.Role || before.Status != after.Status
+}
+
+// Kullanıcının mevcut access token'larını geçersiz kılar. Değişiklik kaydedildikten
+// sonra çağrıldığı için hata işlemi geri almaz, sadece loglanır.
+func revokeUserTokens(ctx context.Context, userID int64) {
+	if err := jwt.RevokeUserTokens(ctx, userID); err != nil {
+		logger.Error("Kullanıcı token'ları geçersiz kılınamadı (kullanıcı %d): %v", userID, err)
+	}
+}
+
+// Versiyon çakışmasını 412'ye, diğer hataları 500'e çevirir
+func wrapUpdateErr(err error) error {
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return errorx.ErrPreconditionFailed
+	}
+	return errorx.WrapErr(errorx.ErrInternal, err)
+}
+
+func (s *UserService) ListDeleted(ctx context.Context, params *query.Params) ([]model.User, error) {
+	users, err := s.userRepo.ListDeleted(ctx, params)
+	if err != nil {
+		return nil, errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	return users, nil
+}
+
+func (s *UserService) Restore(ctx context.Context, id int64) error {
+	user, err := s.userRepo.GetDeletedByID(ctx, id)
+	if err != nil {
+		return errorx.WrapMsg(errorx.ErrNotFound, "Geri yüklenecek silinmiş kullanıcı bulunamadı")
+	}
+	if user.IsAnonymized() {
+		return errorx.WrapMsg(errorx.ErrInvalidRequest, "Kişisel verileri temizlenmiş kullanıcı geri yüklenemez")
+	}
+
+	// Silindikten sonra aynı e-posta veya telefonla yeni bir hesap açılmış olabilir
+	exists, err := s.userRepo.ExistsByEmail(ctx, user.Email)
+	if err != nil {
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
+	if exists {
+		return errorx.WrapMsg(errorx.ErrDuplicate, "Bu e-posta adresi başka bir aktif kullanıcı tarafından kullanılıyor")
+	}
+	if err = s.checkPhoneAvailable(ctx, user.Phone); err != nil {
+		return err
+	}
+	// Kullanıcı adı da sadece silinmemiş kayıtlar arasında benzersiz; biçimi değişmiş
+	// kurallara göre tekrar doğrulanmaz
+	if user.Username != "" {
+		exists, err = s.userRepo.ExistsByUsername(ctx, user.Username)
+		if err != nil {
+			return errorx.WrapErr(errorx.ErrInternal, err)
+		}
+		if exists {
+			return errorx.WrapMsg(errorx.ErrDuplicate, "Bu kullanıcı adı başka bir aktif kullanıcı tarafından kullanılıyor")
+		}
+	}
+
+	if err = s.userRepo.Restore(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errorx.WrapMsg(errorx.ErrNotFound, "Geri yüklenecek silinmiş kullanıcı bulunamadı")
+		}
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// Kullanıcıyı (silinmiş olsun ya da olmasın) kalıcı olarak siler
+func (s *UserService) HardDelete(ctx context.Context, id int64) error {
+	if _, err := s.userRepo.GetByID(ctx, id); err != nil {
+		if _, err = s.userRepo.GetDeletedByID(ctx, id); err != nil {
+			return errorx.WrapMsg(errorx.ErrNotFound, "Silinecek kullanıcı bulunamadı")
+		}
+	}
+
+	if err := s.userRepo.ForceDelete(ctx, id); err != nil {
+		return errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// Saklama süresi dolmuş silinmiş kullanıcıları kalıcı siler veya anonimleştirir
+func (s *UserService) PurgeDeleted(ctx context.Context, retention time.Duration, mode string) (int64, error) {
+	before := time.Now().Add(-retention)
+
+	var (
+		n   int64
+		err error
+	)
+	switch mode {
+	case RetentionModeAnonymize:
+		n, err = s.userRepo.AnonymizeDeleted(ctx, before)
+	case RetentionModePurge:
+		n, err = s.userRepo.PurgeDeleted(ctx, before)
+	default:
+		return 0, errorx.WrapMsg(errorx.ErrInvalidRequest, "Geçersiz saklama modu: "+mode)
+	}
+	if err != nil {
+		return 0, errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/internal/model"
+	"github.com/Furkanturan8/goftr-template/internal/repository"
+	"github.com/Furkanturan8/goftr-template/pkg/email"
+	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
+	"strings"
+	"time"
+)
+
+// Süresi dolan ban/askı kaldırılırken geçmişe yazılan neden
+const statusExpiredReason = "Süre doldu"
+
+// StatusChange bir durum geçişi isteğidir. ActorID nil ise değişikliği sistem yapar.
+type StatusChange struct {
+	Status    model.Status
+	Reason    string
+	ExpiresAt *time.Time
+	ActorID   *int64
+	Version   int64 // 0 değilse kaydın güncel versiyonu ile eşleşmeli (If-Match)
+}
+
+// UserStatusService kullanıcı durumlarını geçiş kurallarına göre değiştirir,
+// her geçişi geçmişe yazar ve kullanıcıyı e-posta ile bilgilendirir
+type UserStatusService struct {
+	txManager  repository.ITxManager
+	userRepo   repository.IUserRepository
+	statusRepo repository.IUserStatusRepository
+	mailer     *email.Email
+}
+
+func NewUserStatusService(tx repository.ITxManager, u repository.IUserRepository, h repository.IUserStatusRepository, mailer *email.Email) *UserStatusService {
+	return &UserStatusService{
+		txManager:  tx,
+		userRepo:   u,
+		statusRepo: h,
+		mailer:     mailer,
+	}
+}
+
+// ChangeStatus kullanıcının durumunu değiştirir. Ban ve askı için neden zorunludur,
+// bitiş zamanı verilirse job tarafından süre dolunca kullanıcı tekrar aktif edilir.
+func (s *UserStatusService) ChangeStatus(ctx context.Context, userID int64, change StatusChange) (*model.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errorx.WrapMsg(errorx.ErrNotFound, "Kullanıcı bulunamadı")
+	}
+	if change.Version > 0 && change.Version != user.Version {
+		return nil, errorx.ErrPreconditionFailed
+	}
+	if change.ActorID != nil && *change.ActorID == userID {
+		return nil, errorx.WrapMsg(errorx.ErrForbidden, "Kendi durumunuzu değiştiremezsiniz")
+	}
+
+	if err = validateStatusChange(user.Status, change); err != nil {
+		return nil, err
+	}
+
+	from := user.Status
+	if err = s.apply(ctx, user, change); err != nil {
+		return nil, wrapUpdateErr(err)
+	}
+
+	s.notify(user, from)
+	return user, nil
+}
+
+// History kullanıcının durum geçmişini döner
+func (s *UserStatusService) History(ctx context.Context, userID int64) ([]model.UserStatusHistory, error) {
+	items, err := s.statusRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, errorx.WrapErr(errorx.ErrInternal, err)
+	}
+	return items, nil
+}
+
+// LiftExpired süresi dolmuş ban ve askıları kaldırır, kaldırılan kullanıcı sayısını döner
+func (s *UserStatusService) LiftExpired(ctx context.Context) (int, error) {
+	users, err := s.userRepo.ListStatusExpired(ctx, time.Now())
+	if err != nil {
+		return 0, errorx.WrapErr(errorx.ErrInternal, err)
+	}
+
+	lifted := 0
+	for i := range users {
+		user := &users[i]
+		from := user.Status
+		if err = s.apply(ctx, user, StatusChange{Status: model.StatusActive, Reason: statusExpiredReason}); err != nil {
+			logger.Error("Süresi dolan durum kaldırılamadı (kullanıcı %d): %v", user.ID, err)
+			continue
+		}
+		s.notify(user, from)
+		lifted++
+	}
+
+	return lifted, nil
+}
+
+// Geçiş kurallarını kontrol eder
+func validateStatusChange(from model.Status, change StatusChange) error {
+	if !change.Status.Valid() {
+		return errorx.WrapMsg(errorx.ErrValidation, "Geçersiz durum: "+string(change.Status))
+	}
+	if from == change.Status {
+		return errorx.WrapMsg(errorx.ErrValidation, "Kullanıcı zaten bu durumda")
+	}
+	if !from.CanTransitionTo(change.Status) {
+		return errorx.WrapMsg(errorx.ErrValidation, fmt.Sprintf("%s durumundan %s durumuna geçilemez", from, change.Status))
+	}
+
+	if change.Status.Restrictive() {
+		if strings.TrimSpace(change.Reason) == "" {
+			return errorx.WrapMsg(errorx.ErrValidation, "Ban ve askıya alma için neden zorunlu")
+		}
+		if change.ExpiresAt != nil && !change.ExpiresAt.After(time.Now()) {
+			return errorx.WrapMsg(errorx.ErrValidation, "Bitiş zamanı gelecekte olmalı")
+		}
+	} else if change.ExpiresAt != nil {
+		return errorx.WrapMsg(errorx.ErrValidation, "Bitiş zamanı sadece ban ve askı için verilebilir")
+	}
+	return nil
+}
+
+// Kullanıcının durum alanlarını günceller ve geçmişe yazar (tek transaction)
+func (s *UserStatusService) apply(ctx context.Context, user *model.User, change StatusChange) error {
+	now := time.Now()
+	history := &model.UserStatusHistory{
+		UserID:     user.ID,
+		FromStatus: user.Status,
+		ToStatus:   change.Status,
+		Reason:     strings.TrimSpace(change.Reason),
+		ChangedBy:  change.ActorID,
+		ExpiresAt:  change.ExpiresAt,
+	}
+
+	user.Status = change.Status
+	user.StatusReason = history.Reason
+	user.StatusChangedBy = change.ActorID
+	user.StatusChangedAt = &now
+	user.StatusExpiresAt = change.ExpiresAt
+
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		err := s.userRepo.UpdateFields(ctx, user,
+			"status", "status_reason", "status_changed_by", "status_changed_at", "status_expires_at")
+		if err != nil {
+			return err
+		}
+		return s.statusRepo.Create(ctx, history)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Token'daki durum bilgisi eskidi
+	revokeUserTokens(ctx, user.ID)
+	return nil
+}
+
+// Kullanıcıya durum değişikliğini bildirir. Gönderim hatası işlemi geri almaz.
+func (s *UserStatusService) notify(user *model.User, from model.Status) {
+	if s.mailer == nil || user.Email == "" {
+		return
+	}
+
+	subject, body := statusEmail(user, from)
+	if err := s.mailer.Send(user.Email, subject, body); err != nil {
+		logger.Error("Durum değişikliği e-postası gönderilemedi (kullanıcı %d): %v", user.ID, err)
+	}
+}
+
+func statusEmail(user *model.User, from model.Status) (string, string) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hello %s,\n\n", user.FirstName)
+
+	var subject string
+	switch user.Status {
+	case model.StatusBanned:
+		subject = "Your account has been banned"
+		b.WriteString("Your account has been banned.\n")
+	case model.StatusSuspended:
+		subject = "Your account has been suspended"
+		b.WriteString("Your account has been suspended.\n")
+	case model.StatusInactive:
+		subject = "Your account has been deactivated"
+		b.WriteString("Your account has been deactivated.\n")
+	default:
+		subject = "Your account is active again"
+		if from.Restrictive() {
+			fmt.Fprintf(&b, "The %s on your account has been lifted. You can sign in again.\n", restrictionName(from))
+		} else {
+			b.WriteString("Your account has been activated. You can sign in again.\n")
+		}
+	}
+
+	if user.Status.Restrictive() && user.StatusReason != "" {
+		fmt.Fprintf(&b, "\nReason: %s\n", user.StatusReason)
+	}
+	if user.StatusExpiresAt != nil {
+		fmt.Fprintf(&b, "Until: %s\n", user.StatusExpiresAt.UTC().Format(time.RFC1123))
+	}
+
+	return subject, b.String()
+}
+
+func restrictionName(status model.Status) string {
+	if status == model.StatusBanned {
+		return "ban"
+	}
+	return "suspension"
+}
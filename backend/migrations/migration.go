@@ -2,98 +2,264 @@ package migrations
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"github.com/uptrace/bun"
+	"sort"
+	"strings"
+	"time"
 )
 
+// Aynı veritabanında migration çalıştıran process'lerin paylaştığı advisory lock anahtarı
+const migrationLockID int64 = 0x676f6674725f6d69
+
 // Migration bir migration'ı temsil eder
 type Migration struct {
 	Version string
+	Name    string
 	Up      string
 	Down    string
 }
 
+// Checksum Up SQL'inin hash'idir; uygulandıktan sonra değiştirilen migration'ları yakalamak için tutulur
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
 var Migrations = []Migration{}
 
-// Up belirtilen sayıda migration'ı yukarı doğru çalıştırır
-func Up(ctx context.Context, db *bun.DB, step int) error {
-	// Çalıştırılacak migration sayısını belirle
-	migrationsToRun := Migrations
-	if step > 0 && step < len(Migrations) {
-		migrationsToRun = Migrations[:step]
-	}
+// appliedMigration schema_migrations tablosundaki bir kayıttır
+type appliedMigration struct {
+	bun.BaseModel `bun:"table:schema_migrations"`
+
+	Version   string    `bun:"version,pk"`
+	Checksum  string    `bun:"checksum,notnull"`
+	AppliedAt time.Time `bun:"applied_at,notnull,default:current_timestamp"`
+}
 
-	// Her migration için
-	for _, migration := range migrationsToRun {
-		// Transaction başlat
-		tx, err := db.BeginTx(ctx, nil)
+// Up uygulanmamış migration'lardan belirtilen sayıda olanı (0 ise tümünü) sırayla uygular
+func Up(ctx context.Context, db *bun.DB, step int) error {
+	return withLock(ctx, db, func(conn bun.Conn) error {
+		applied, err := loadApplied(ctx, conn)
 		if err != nil {
-			return fmt.Errorf("transaction başlatılamadı: %v", err)
+			return err
 		}
-
-		// Migration'ı uygula
-		if _, err = tx.ExecContext(ctx, migration.Up); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("migration uygulanamadı (version %s): %v", migration.Version, err)
+		if err = verifyChecksums(applied); err != nil {
+			return err
 		}
 
-		// Transaction'ı commit et
-		if err = tx.Commit(); err != nil {
-			return fmt.Errorf("transaction commit edilemedi: %v", err)
-		}
+		count := 0
+		for _, migration := range Migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if step > 0 && count >= step {
+				break
+			}
 
-		fmt.Printf("Migration uygulandı: %s\n", migration.Version)
-	}
+			// Migration ve kaydı aynı transaction'da yazılır; yarım kalan migration kaydedilmez
+			err = conn.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+					return err
+				}
+				_, err := tx.NewInsert().
+					Model(&appliedMigration{Version: migration.Version, Checksum: migration.Checksum()}).
+					Exec(ctx)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration uygulanamadı (version %s): %v", migration.Version, err)
+			}
 
-	return nil
+			count++
+			fmt.Printf("Migration uygulandı: %s_%s\n", migration.Version, migration.Name)
+		}
+
+		if count == 0 {
+			fmt.Println("Uygulanacak migration yok")
+		}
+		return nil
+	})
 }
 
-// Down belirtilen sayıda migration'ı geri alır
+// Down uygulanmış migration'lardan belirtilen sayıda olanı (0 ise tümünü) sondan başa doğru geri alır
 func Down(ctx context.Context, db *bun.DB, step int) error {
-	// Geri alınacak migration sayısını belirle
-	totalMigrations := len(Migrations)
-	startIndex := totalMigrations - 1
-	endIndex := 0
+	return withLock(ctx, db, func(conn bun.Conn) error {
+		applied, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		// Kodda karşılığı olmayan kayıtlar (ör. daha yeni bir sürümle uygulanmış) geri alınamaz;
+		// eskileri onların altından geri almak şemayı bozar
+		if unknown := unknownVersions(applied); len(unknown) > 0 {
+			return fmt.Errorf("kodda tanımlı olmayan migration'lar uygulanmış (%s), önce onları içeren sürümle geri alın", strings.Join(unknown, ", "))
+		}
+
+		count := 0
+		for i := len(Migrations) - 1; i >= 0; i-- {
+			migration := Migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if step > 0 && count >= step {
+				break
+			}
 
-	if step > 0 {
-		endIndex = totalMigrations - step
-		if endIndex < 0 {
-			endIndex = 0
+			err = conn.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+					return err
+				}
+				_, err := tx.NewDelete().
+					Model((*appliedMigration)(nil)).
+					Where("version = ?", migration.Version).
+					Exec(ctx)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration geri alınamadı (version %s): %v", migration.Version, err)
+			}
+
+			count++
+			fmt.Printf("Migration geri alındı: %s_%s\n", migration.Version, migration.Name)
+		}
+
+		if count == 0 {
+			fmt.Println("Geri alınacak migration yok")
 		}
+		return nil
+	})
+}
+
+// Baseline tablo takibi öncesinde kurulmuş veritabanları içindir: version'a kadar olan
+// migration'ları çalıştırmadan uygulanmış olarak kaydeder
+func Baseline(ctx context.Context, db *bun.DB, version string) error {
+	if _, ok := findMigration(version); !ok {
+		return fmt.Errorf("migration bulunamadı (version %s)", version)
 	}
 
-	// Migration'ları sondan başa doğru geri al
-	for i := startIndex; i >= endIndex; i-- {
-		migration := Migrations[i]
+	return withLock(ctx, db, func(conn bun.Conn) error {
+		for _, migration := range Migrations {
+			if migration.Version > version {
+				break
+			}
+			_, err := conn.NewInsert().
+				Model(&appliedMigration{Version: migration.Version, Checksum: migration.Checksum()}).
+				On("CONFLICT (version) DO NOTHING").
+				Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("migration kaydedilemedi (version %s): %v", migration.Version, err)
+			}
+		}
+		fmt.Printf("Migration'lar %s sürümüne kadar uygulanmış olarak işaretlendi\n", version)
+		return nil
+	})
+}
 
-		// Transaction başlat
-		tx, err := db.BeginTx(ctx, nil)
+// Status migration'ların veritabanında uygulanıp uygulanmadığını gösterir
+func Status(ctx context.Context, db *bun.DB) (string, error) {
+	var b strings.Builder
+	err := withLock(ctx, db, func(conn bun.Conn) error {
+		applied, err := loadApplied(ctx, conn)
 		if err != nil {
-			return fmt.Errorf("transaction başlatılamadı: %v", err)
+			return err
 		}
 
-		// Migration'ı geri al
-		if _, err = tx.ExecContext(ctx, migration.Down); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("migration geri alınamadı (version %s): %v", migration.Version, err)
+		pending := 0
+		b.WriteString("Migration Durumu:\n\n")
+		for _, m := range Migrations {
+			record, ok := applied[m.Version]
+			switch {
+			case !ok:
+				pending++
+				fmt.Fprintf(&b, "- %s_%s  bekliyor\n", m.Version, m.Name)
+			case record.Checksum != m.Checksum():
+				fmt.Fprintf(&b, "- %s_%s  uygulandı (%s)  UYARI: uygulandıktan sonra değiştirilmiş\n", m.Version, m.Name, record.AppliedAt.Format(time.DateTime))
+			default:
+				fmt.Fprintf(&b, "- %s_%s  uygulandı (%s)\n", m.Version, m.Name, record.AppliedAt.Format(time.DateTime))
+			}
+		}
+		for _, version := range unknownVersions(applied) {
+			fmt.Fprintf(&b, "- %s  uygulandı (%s)  UYARI: kodda tanımlı değil\n", version, applied[version].AppliedAt.Format(time.DateTime))
 		}
 
-		// Transaction'ı commit et
-		if err = tx.Commit(); err != nil {
-			return fmt.Errorf("transaction commit edilemedi: %v", err)
+		fmt.Fprintf(&b, "\n%d uygulandı, %d bekliyor\n", len(applied), pending)
+		return nil
+	})
+	return b.String(), err
+}
+
+// İşlemi tek bir bağlantı üzerinde advisory lock altında çalıştırır; aynı anda başlatılan
+// diğer runner'lar kilit bırakılana kadar bekler ve güncel durumu görür
+func withLock(ctx context.Context, db *bun.DB, fn func(conn bun.Conn) error) error {
+	// Advisory lock bağlantıya aittir, kilit ve işlemler aynı bağlantıda yapılmalı
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("veritabanı bağlantısı alınamadı: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock(?)", migrationLockID); err != nil {
+		return fmt.Errorf("migration kilidi alınamadı: %v", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock(?)", migrationLockID); err != nil {
+			fmt.Printf("Migration kilidi bırakılamadı: %v\n", err)
 		}
+	}()
+
+	_, err = conn.NewCreateTable().
+		Model((*appliedMigration)(nil)).
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("schema_migrations tablosu oluşturulamadı: %v", err)
+	}
+
+	return fn(conn)
+}
 
-		fmt.Printf("Migration geri alındı: %s\n", migration.Version)
+func loadApplied(ctx context.Context, conn bun.Conn) (map[string]appliedMigration, error) {
+	var records []appliedMigration
+	if err := conn.NewSelect().Model(&records).Order("version ASC").Scan(ctx); err != nil {
+		return nil, fmt.Errorf("uygulanmış migration'lar okunamadı: %v", err)
 	}
 
+	applied := make(map[string]appliedMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// Uygulanmış bir migration'ın SQL'i değiştiyse veritabanı koddan farklıdır; yeni migration eklenmeli
+func verifyChecksums(applied map[string]appliedMigration) error {
+	for _, m := range Migrations {
+		if record, ok := applied[m.Version]; ok && record.Checksum != m.Checksum() {
+			return fmt.Errorf("migration %s uygulandıktan sonra değiştirilmiş (checksum uyuşmuyor), değişiklik için yeni bir migration ekleyin", m.Version)
+		}
+	}
 	return nil
 }
 
-// Status mevcut migration'ların listesini gösterir
-func Status(ctx context.Context, db *bun.DB) (string, error) {
-	status := "Mevcut Migration'lar:\n\n"
+// Veritabanında uygulanmış görünen ama kodda olmayan version'ları sıralı döner
+func unknownVersions(applied map[string]appliedMigration) []string {
+	var unknown []string
+	for version := range applied {
+		if _, ok := findMigration(version); !ok {
+			unknown = append(unknown, version)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+func findMigration(version string) (Migration, bool) {
 	for _, m := range Migrations {
-		status += fmt.Sprintf("- %s\n", m.Version)
+		if m.Version == version {
+			return m, true
+		}
 	}
-	return status, nil
+	return Migration{}, false
 }
@@ -1,48 +1,86 @@
 package migrations
 
 import (
-	"os"
-	"path/filepath"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
 )
 
-// IMPORTANT INFO: when you wanna add a new table or sql file, you should add it to migration list for up and down
+// Yeni migration için sql/ altına sıradaki version ile NNNNNN_isim.up.sql ve
+// NNNNNN_isim.down.sql dosyaları eklenir; dosyalar binary'ye gömülür.
+//
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+var fileNamePattern = regexp.MustCompile(`^(\d{6})_([a-z0-9_]+)\.(up|down)\.sql$`)
+
 func init() {
-	migrations := []Migration{
-		{
-			Version: "000001",
-			Up:      readSQLFile("000001_create_enums.sql"),
-			Down: `
-                DROP TYPE IF EXISTS user_role;
-                DROP TYPE IF EXISTS user_status;
-            `,
-		},
-		{
-			Version: "000002",
-			Up:      readSQLFile("000002_create_users.sql"),
-			Down: `
-                DROP TRIGGER IF EXISTS update_users_updated_at ON users;
-                DROP FUNCTION IF EXISTS update_updated_at_column();
-                DROP TABLE IF EXISTS users CASCADE;
-            `,
-		},
-		{
-			Version: "000003",
-			Up:      readSQLFile("000003_create_auth_tables.sql"),
-			Down: `
-                DROP TABLE IF EXISTS sessions CASCADE;
-                DROP TABLE IF EXISTS token_blacklists CASCADE;
-                DROP TABLE IF EXISTS tokens CASCADE;
-            `,
-		},
+	dir, err := fs.Sub(sqlFiles, "sql")
+	if err != nil {
+		panic(err)
+	}
+	migrations, err := Load(dir)
+	if err != nil {
+		panic(err)
 	}
-
 	Migrations = append(Migrations, migrations...)
 }
 
-func readSQLFile(filename string) string {
-	content, err := os.ReadFile(filepath.Join("migrations", "sql", filename))
+// Load fsys kökündeki up/down SQL çiftlerini version sırasıyla okur. Version'lar
+// 000001'den başlayıp boşluksuz artmalı ve her version için tek bir up/down çifti olmalıdır.
+func Load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+
+	byVersion := make(map[string]*Migration)
+	for _, file := range names {
+		match := fileNamePattern.FindStringSubmatch(file)
+		if match == nil {
+			return nil, fmt.Errorf("geçersiz migration dosya adı: %s (NNNNNN_isim.up.sql veya NNNNNN_isim.down.sql olmalı)", file)
+		}
+		version, name, direction := match[1], match[2], match[3]
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("aynı version'a sahip birden fazla migration: %s_%s ve %s_%s", version, m.Name, version, name)
+		}
+
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	for i, m := range migrations {
+		if n, _ := strconv.Atoi(m.Version); n != i+1 {
+			return nil, fmt.Errorf("migration sırasında boşluk: %06d bekleniyordu, %s bulundu", i+1, m.Version)
+		}
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %s_%s için up dosyası yok veya boş", m.Version, m.Name)
+		}
+		if m.Down == "" {
+			return nil, fmt.Errorf("migration %s_%s için down dosyası yok veya boş", m.Version, m.Name)
+		}
 	}
-	return string(content)
+	return migrations, nil
 }
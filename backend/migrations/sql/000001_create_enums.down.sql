@@ -0,0 +1,2 @@
+DROP TYPE IF EXISTS user_role;
+DROP TYPE IF EXISTS user_status;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS sessions CASCADE;
+DROP TABLE IF EXISTS token_blacklists CASCADE;
+DROP TABLE IF EXISTS tokens CASCADE;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_deleted_at;
+DROP INDEX IF EXISTS users_email_unique_active;
+CREATE INDEX idx_users_email ON users (email);
+ALTER TABLE users ADD CONSTRAINT users_email_unique UNIQUE (email);
@@ -0,0 +1,7 @@
+-- Silinmiş kullanıcıların e-posta adresleri tekrar kullanılabilsin diye
+-- unique kısıtını sadece aktif (silinmemiş) kayıtlar için uygula
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_unique;
+DROP INDEX IF EXISTS idx_users_email;
+
+CREATE UNIQUE INDEX users_email_unique_active ON users (email) WHERE deleted_at IS NULL;
+CREATE INDEX idx_users_deleted_at ON users (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Optimistic locking için kayıt versiyonu (ETag / If-Match)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+-- Kullanıcının kendi hesabını silme talebi (bekleme süresi sonunda anonimleştirilir)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_requested_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_users_deletion_requested_at ON users (deletion_requested_at) WHERE deletion_requested_at IS NOT NULL;
@@ -0,0 +1,8 @@
+-- Postgres enum değerlerini silemediği için 'suspended' tipte kalır
+DROP TABLE IF EXISTS user_status_histories;
+UPDATE users SET status = 'inactive' WHERE status = 'suspended';
+DROP INDEX IF EXISTS idx_users_status_expires_at;
+ALTER TABLE users DROP COLUMN IF EXISTS status_expires_at;
+ALTER TABLE users DROP COLUMN IF EXISTS status_changed_at;
+ALTER TABLE users DROP COLUMN IF EXISTS status_changed_by;
+ALTER TABLE users DROP COLUMN IF EXISTS status_reason;
@@ -0,0 +1,24 @@
+-- Süreli askıya alma için yeni durum
+ALTER TYPE user_status ADD VALUE IF NOT EXISTS 'suspended';
+
+-- Kullanıcının güncel durumunun nedeni, değiştiren admin ve bitiş zamanı
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_reason TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_changed_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_users_status_expires_at ON users (status_expires_at) WHERE status_expires_at IS NOT NULL;
+
+-- Durum geçmişi (changed_by NULL ise değişikliği sistem yapmıştır)
+CREATE TABLE IF NOT EXISTS user_status_histories (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_status user_status NOT NULL,
+    to_status user_status NOT NULL,
+    reason TEXT,
+    changed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_status_histories_user_id ON user_status_histories (user_id, created_at);
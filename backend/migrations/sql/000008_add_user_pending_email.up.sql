@@ -0,0 +1,2 @@
+-- Onay bekleyen e-posta değişikliği (yeni adres onaylanınca email alanına taşınır)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS pending_email VARCHAR(64);
@@ -0,0 +1,7 @@
+-- Sadece telefonu olan kullanıcılar e-posta zorunluluğu geri gelmeden önce silinmeli
+DROP INDEX IF EXISTS users_phone_unique_active;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_or_phone;
+ALTER TABLE users DROP COLUMN IF EXISTS two_factor_enabled;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_verified_at;
+ALTER TABLE users DROP COLUMN IF EXISTS phone;
+ALTER TABLE users ALTER COLUMN email SET NOT NULL;
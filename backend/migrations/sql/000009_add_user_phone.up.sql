@@ -0,0 +1,9 @@
+-- E.164 telefon numarası, doğrulama zamanı ve SMS ile iki adımlı doğrulama.
+-- Sadece telefonla kayıt olunabildiği için e-posta artık zorunlu değil.
+ALTER TABLE users ALTER COLUMN email DROP NOT NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone VARCHAR(16);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verified_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS two_factor_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD CONSTRAINT users_email_or_phone CHECK (email IS NOT NULL OR phone IS NOT NULL);
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_phone_unique_active ON users (phone) WHERE deleted_at IS NULL AND phone IS NOT NULL;
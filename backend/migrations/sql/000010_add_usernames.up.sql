@@ -0,0 +1,5 @@
+-- Opsiyonel kullanıcı adı; benzersizlik büyük/küçük harf duyarsız ve sadece silinmemiş kayıtlarda
+ALTER TABLE users ADD COLUMN IF NOT EXISTS username VARCHAR(30);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS username_changed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_unique_active ON users (lower(username)) WHERE deleted_at IS NULL AND username IS NOT NULL;
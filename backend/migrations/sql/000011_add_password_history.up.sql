@@ -0,0 +1,7 @@
+-- Tekrar kullanımı engellemek için önceki şifre hash'leri (en yenisi başta) ve
+-- şifre yaşı kontrolü için son değişiklik zamanı. Mevcut şifreler oluşturulma
+-- zamanında belirlenmiş kabul edilir.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_history TEXT[];
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE users SET password_changed_at = created_at WHERE password_changed_at IS NULL;
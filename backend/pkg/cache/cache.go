@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/config"
+	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
+	"time"
+)
+
+// Desteklenen cache backend'leri (CACHE_DRIVER)
+const (
+	DriverRedis  = "redis"
+	DriverMemory = "memory"
+	DriverNone   = "none"
+)
+
+// ErrNotFound key cache'te yoksa veya süresi dolmuşsa Get tarafından döner
+var ErrNotFound = errors.New("cache: key bulunamadı")
+
+// Store cache backend'lerinin ortak arayüzü. Değerler JSON olarak saklanır;
+// expiration 0 ise key süresiz tutulur.
+type Store interface {
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	// SetNX key yoksa yazar ve true döner; kısa süreli kilitler için kullanılır
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+	Get(ctx context.Context, key string, dest interface{}) error
+	Delete(ctx context.Context, key string) error
+	// DeleteMany glob pattern'ine (*, ?) uyan tüm key'leri siler
+	DeleteMany(ctx context.Context, pattern string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	Expire(ctx context.Context, key string, expiration time.Duration) error
+	// Incr key'deki sayacı atomik olarak bir artırır; key yeni oluştuysa expiration uygulanır
+	Incr(ctx context.Context, key string, expiration time.Duration) (int64, error)
+	// SetWithTags değeri yazar ve key'i verilen tag'lerle (ör. "user:42", "users:list") ilişkilendirir.
+	// Tag'ler birikir: key tekrar yazıldığında eski tag'ler kalır, Set ise tag'lere dokunmaz.
+	SetWithTags(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error
+	// InvalidateTags tag'lerden herhangi birini taşıyan tüm key'leri siler
+	InvalidateTags(ctx context.Context, tags ...string) error
+	Close() error
+}
+
+// Tag set'lerinin key öneki; tag'ler normal key'lerle çakışmaz
+const tagKeyPrefix = "tag:"
+
+func tagKey(tag string) string {
+	return tagKeyPrefix + tag
+}
+
+// Açılışta Redis'e ulaşılabildiğini kontrol ederken beklenen süre
+const redisPingTimeout = 10 * time.Second
+
+// New yapılandırmadaki driver'a göre cache backend'ini oluşturur. Açılışta Redis'e
+// ulaşılamazsa Fallback verilmişse o backend kullanılır; verilmemişse Redis client'ı
+// yine döner ve bağlantı gelene kadar işlemler hata alır. Token iptali, OTP, kilit ve
+// rate limit instance'lar arasında paylaşıldığı için sessizce belleğe geçilmez.
+func New(cfg *config.CacheConfig, redisCfg *config.RedisConfig) (Store, error) {
+	switch cfg.Driver {
+	case DriverRedis, "":
+		if cfg.Fallback != "" && cfg.Fallback != DriverMemory && cfg.Fallback != DriverNone {
+			return nil, fmt.Errorf("geçersiz cache fallback: %q (memory veya none olmalı)", cfg.Fallback)
+		}
+		redisCache, err := NewRedisCache(redisCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), redisPingTimeout)
+		err = redisCache.Ping(ctx)
+		cancel()
+		if err != nil {
+			if cfg.Fallback != "" {
+				_ = redisCache.Close()
+				logger.Error("Redis'e ulaşılamadı, %s cache kullanılıyor: %v", cfg.Fallback, err)
+				return New(&config.CacheConfig{Driver: cfg.Fallback, MemoryMaxEntries: cfg.MemoryMaxEntries}, redisCfg)
+			}
+			logger.Error("Redis'e ulaşılamadı, bağlantı kurulana kadar cache işlemleri hata dönecek: %v", err)
+		}
+
+		if !cfg.L1Enabled {
+			return redisCache, nil
+		}
+		l1 := NewMemoryCache(cfg.L1MaxEntries)
+		return NewTieredCache(l1, redisCache, redisCache.Broadcaster(invalidationChannel), time.Duration(cfg.L1TTLSeconds)*time.Second)
+	case DriverMemory:
+		return NewMemoryCache(cfg.MemoryMaxEntries), nil
+	case DriverNone:
+		return NewNoopCache(), nil
+	}
+	return nil, fmt.Errorf("bilinmeyen cache driver: %q", cfg.Driver)
+}
+
+// Repository ve servislere enjekte edilemeyen yerler (ör. jwt paketi) için varsayılan store
+var defaultCache Store
+
+// SetDefault paket seviyesindeki fonksiyonların kullanacağı store'u ayarlar
+func SetDefault(store Store) {
+	defaultCache = store
+}
+
+// Global fonksiyonlar
+func Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if defaultCache == nil {
+		return errorx.WrapMsg(errorx.ErrInternal, "Cache başlatılmadı: Set işlemi gerçekleştirilemedi")
+	}
+	return defaultCache.Set(ctx, key, value, expiration)
+}
+
+func SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	if defaultCache == nil {
+		return false, errorx.WrapMsg(errorx.ErrInternal, "Cache başlatılmadı: SetNX işlemi gerçekleştirilemedi")
+	}
+	return defaultCache.SetNX(ctx, key, value, expiration)
+}
+
+func Get(ctx context.Context, key string, dest interface{}) error {
+	if defaultCache == nil {
+		return errorx.WrapMsg(errorx.ErrInternal, "Cache başlatılmadı: Get işlemi gerçekleştirilemedi")
+	}
+	return defaultCache.Get(ctx, key, dest)
+}
+
+func Delete(ctx context.Context, key string) error {
+	if defaultCache == nil {
+		return errorx.WrapMsg(errorx.ErrInternal, "Cache başlatılmadı: Delete işlemi gerçekleştirilemedi")
+	}
+	return defaultCache.Delete(ctx, key)
+}
+
+func DeleteMany(ctx context.Context, pattern string) error {
+	if defaultCache == nil {
+		return errorx.WrapMsg(errorx.ErrInternal, "Cache başlatılmadı: DeleteMany işlemi gerçekleştirilemedi")
+	}
+	return defaultCache.DeleteMany(ctx, pattern)
+}
+
+func Exists(ctx context.Context, key string) (bool, error) {
+	if defaultCache == nil {
+		return false, errorx.WrapMsg(errorx.ErrInternal, "Cache başlatılmadı: Exists işlemi gerçekleştirilemedi")
+	}
+	return defaultCache.Exists(ctx, key)
+}
+
+func Expire(ctx context.Context, key string, expiration time.Duration) error {
+	if defaultCache == nil {
+		return errorx.WrapMsg(errorx.ErrInternal, "Cache başlatılmadı: Expire işlemi gerçekleştirilemedi")
+	}
+	return defaultCache.Expire(ctx, key, expiration)
+}
+
+func Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	if defaultCache == nil {
+		return 0, errorx.WrapMsg(errorx.ErrInternal, "Cache başlatılmadı: Incr işlemi gerçekleştirilemedi")
+	}
+	return defaultCache.Incr(ctx, key, expiration)
+}
+
+func SetWithTags(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	if defaultCache == nil {
+		return errorx.WrapMsg(errorx.ErrInternal, "Cache başlatılmadı: SetWithTags işlemi gerçekleştirilemedi")
+	}
+	return defaultCache.SetWithTags(ctx, key, value, expiration, tags...)
+}
+
+func InvalidateTags(ctx context.Context, tags ...string) error {
+	if defaultCache == nil {
+		return errorx.WrapMsg(errorx.ErrInternal, "Cache başlatılmadı: InvalidateTags işlemi gerçekleştirilemedi")
+	}
+	return defaultCache.InvalidateTags(ctx, tags...)
+}
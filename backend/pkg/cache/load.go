@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// Aynı key'i başka bir instance yüklerken tutulan kilidin süresi. Kilit yükleme boyunca
+	// uzatılır; diğer instance'lar en fazla bu kadar bekleyip kendileri yükler.
+	loadLockTTL          = 5 * time.Second
+	loadLockPollInterval = 50 * time.Millisecond
+	loadLockKeyPrefix    = "load:"
+	// Paylaşılan yükleme isteğin context'inden bağımsız çalışır; takılan bir sorgu
+	// aynı key'i bekleyen herkesi bundan uzun bekletmez
+	loadTimeout       = 10 * time.Second
+	defaultLoadJitter = 0.1
+)
+
+// loadEntry GetOrLoad'un cache'e yazdığı zarftır. FreshUntil'den sonra değer
+// bayattır; WithStale verilmişse fiziksel TTL dolana kadar sunulabilir.
+type loadEntry struct {
+	Value      json.RawMessage `json:"v"`
+	FreshUntil int64           `json:"f"` // Unix milisaniye
+}
+
+type loadOptions struct {
+	stale  time.Duration
+	jitter float64
+	tags   []string
+}
+
+type LoadOption func(*loadOptions)
+
+// WithStale süresi dolan değerin d kadar daha sunulmasına izin verir;
+// bu sürede değer arka planda tek bir çağıran tarafından yenilenir.
+func WithStale(d time.Duration) LoadOption {
+	return func(o *loadOptions) { o.stale = d }
+}
+
+// WithJitter TTL'e ±fraction oranında rastgele sapma ekler (varsayılan 0.1).
+// Aynı anda yazılan key'lerin aynı anda düşmesini engeller.
+func WithJitter(fraction float64) LoadOption {
+	return func(o *loadOptions) { o.jitter = fraction }
+}
+
+// WithTags yüklenen değeri verilen tag'lerle yazar (bkz. Store.SetWithTags)
+func WithTags(tags ...string) LoadOption {
+	return func(o *loadOptions) { o.tags = tags }
+}
+
+// GetOrLoad key cache'te varsa döner, yoksa loader ile yükleyip ttl kadar cache'e yazar.
+// Aynı process'te aynı key için eşzamanlı miss'lerde loader bir kez çalışır; diğer
+// instance'lar ise kısa bir Redis kilidi sayesinde cache'in dolmasını bekler.
+// Cache hataları yüklemeyi engellemez, sadece loader hatası döner.
+func GetOrLoad[T any](ctx context.Context, store Store, key string, ttl time.Duration, loader func(ctx context.Context) (T, error), opts ...LoadOption) (T, error) {
+	o := loadOptions{jitter: defaultLoadJitter}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var result T
+	load := func(ctx context.Context, wait bool) ([]byte, error) {
+		return loadEntryValue(ctx, store, key, ttl, o, wait, func(ctx context.Context) (interface{}, error) {
+			return loader(ctx)
+		})
+	}
+
+	if entry := readEntry(ctx, store, key); entry != nil {
+		if err := json.Unmarshal(entry.Value, &result); err == nil {
+			if time.Now().UnixMilli() < entry.FreshUntil {
+				return result, nil
+			}
+			if o.stale > 0 {
+				// Bayat değeri dön, yenilemeyi isteğe bağlı olmayan bir context ile arka planda yap
+				bg, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+				go func() {
+					defer cancel()
+					// Senkron yüklemelerle ayrı tutulur; kilit başkasındaysa yenileme hiçbir şey döndürmez
+					_, _ = loads.do("refresh:"+key, func() ([]byte, error) { return load(bg, false) })
+				}()
+				return result, nil
+			}
+		}
+	}
+
+	// İsteği yapan client bağlantıyı kapatırsa bekleyen diğer çağıranlar da hata almasın
+	data, err := loads.do(key, func() ([]byte, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+		return load(loadCtx, true)
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	// Her çağıran kendi kopyasını alır
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		var zero T
+		return zero, err
+	}
+	return value, nil
+}
+
+// Kilidi alıp loader'ı çalıştırır ve sonucu cache'e yazar. Kilit başka bir instance'taysa
+// wait true ise cache'in dolması beklenir, false ise (arka plan yenilemesi) hiçbir şey yapılmaz.
+// Kilit sadece sahibi tarafından bırakılır; süresi dolup başkasına geçtiyse ona dokunulmaz.
+func loadEntryValue(ctx context.Context, store Store, key string, ttl time.Duration, o loadOptions, wait bool, loader func(ctx context.Context) (interface{}, error)) ([]byte, error) {
+	// Kilit desteklemeyen store'larda (NoopCache) koordinasyon yapılmaz
+	if locker, ok := store.(Locker); ok {
+		lease, err := TryLock(ctx, locker, loadLockKeyPrefix+key, loadLockTTL)
+		switch {
+		case err == nil:
+			defer lease.Release(context.WithoutCancel(ctx))
+		case errors.Is(err, ErrLockHeld):
+			if !wait {
+				return nil, nil
+			}
+			if data := waitForEntry(ctx, store, key); data != nil {
+				return data, nil
+			}
+			// Diğer instance zamanında yazamadı; kendimiz yükleriz
+		default:
+			// Cache'e erişilemiyorsa koordinasyon yapılamaz, doğrudan yükle
+		}
+	}
+
+	value, err := loader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	fresh := jitter(ttl, o.jitter)
+	entry := loadEntry{Value: data, FreshUntil: time.Now().Add(fresh).UnixMilli()}
+	_ = store.SetWithTags(ctx, key, &entry, fresh+o.stale, o.tags...)
+	return data, nil
+}
+
+// Kilit süresi boyunca taze bir değer yazılmasını bekler
+func waitForEntry(ctx context.Context, store Store, key string) []byte {
+	deadline := time.Now().Add(loadLockTTL)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(loadLockPollInterval):
+		}
+		if entry := readEntry(ctx, store, key); entry != nil && time.Now().UnixMilli() < entry.FreshUntil {
+			return entry.Value
+		}
+	}
+	return nil
+}
+
+func readEntry(ctx context.Context, store Store, key string) *loadEntry {
+	var entry loadEntry
+	if err := store.Get(ctx, key, &entry); err != nil || len(entry.Value) == 0 {
+		return nil
+	}
+	return &entry
+}
+
+func jitter(ttl time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 || ttl <= 0 {
+		return ttl
+	}
+	delta := time.Duration(float64(ttl) * fraction * (2*rand.Float64() - 1))
+	return ttl + delta
+}
+
+// flightGroup aynı key için eşzamanlı çağrıları tek çalıştırmada birleştirir (singleflight)
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	wg   sync.WaitGroup
+	data []byte
+	err  error
+}
+
+var loads = &flightGroup{calls: make(map[string]*flightCall)}
+
+func (g *flightGroup) do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.data, call.err
+	}
+	call := &flightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		call.wg.Done()
+	}()
+
+	call.data, call.err = fn()
+	return call.data, call.err
+}
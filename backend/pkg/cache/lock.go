@@ -0,0 +1,176 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// Kilit alınamadığında Lock'un tekrar deneme aralığı
+	lockRetryInterval = 100 * time.Millisecond
+	lockKeyPrefix     = "lock:"
+	// Uzatma başarısız olduktan sonra tekrar deneme aralığı (ttl'in oranı olarak)
+	lockRenewRetryDivisor = 10
+)
+
+var (
+	// ErrLockHeld kilit başka bir sahipteyken TryLock tarafından döner
+	ErrLockHeld = errors.New("cache: kilit başka bir sahipte")
+	// ErrLockLost kilit süresi dolup başkası tarafından alındıysa Release tarafından döner;
+	// kritik bölge başka bir sahiple çakışmış olabilir
+	ErrLockLost = errors.New("cache: kilit kaybedildi")
+)
+
+// Locker instance'lar arası kilit için gereken temel işlemlerdir. RedisCache, MemoryCache
+// ve TieredCache destekler; NoopCache desteklemez.
+type Locker interface {
+	// AcquireLock key boşsa owner adına ttl süreyle alır ve key için artan bir fencing token döner
+	AcquireLock(ctx context.Context, key, owner string, ttl time.Duration) (token int64, acquired bool, err error)
+	// RenewLock kilit hâlâ owner'daysa süresini ttl'e uzatır
+	RenewLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	// ReleaseLock kilidi sadece owner'daysa bırakır
+	ReleaseLock(ctx context.Context, key, owner string) (bool, error)
+}
+
+// Lease alınmış bir kilittir. Süresi ttl/3 aralıkla otomatik uzatılır; son başarılı
+// uzatmadan (veya alımdan) sonra ttl'in üçte ikisi içinde uzatılamazsa kilit süresi
+// dolmadan Context() iptal edilir ve kritik bölge durdurulmalıdır.
+type Lease struct {
+	locker Locker
+	key    string
+	owner  string
+	token  int64
+	ttl    time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	releaseOnce sync.Once
+	releaseErr  error
+}
+
+// Lock kilit alınana veya ctx iptal edilene kadar bekler
+func Lock(ctx context.Context, locker Locker, key string, ttl time.Duration) (*Lease, error) {
+	for {
+		lease, err := TryLock(ctx, locker, key, ttl)
+		if !errors.Is(err, ErrLockHeld) {
+			return lease, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// TryLock kilidi almayı bir kez dener; başkasındaysa ErrLockHeld döner.
+// Lease'in context'i ctx'ten türetilir, ctx iptal edilirse kilit bırakılır.
+func TryLock(ctx context.Context, locker Locker, key string, ttl time.Duration) (*Lease, error) {
+	owner, err := newLockOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	// Kilidin süresi isteğin gönderildiği andan itibaren sayılır
+	start := time.Now()
+	token, acquired, err := locker.AcquireLock(ctx, key, owner, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrLockHeld
+	}
+
+	lease := &Lease{locker: locker, key: key, owner: owner, token: token, ttl: ttl, done: make(chan struct{})}
+	lease.ctx, lease.cancel = context.WithCancel(ctx)
+	go lease.keepAlive(ctx, start)
+	return lease, nil
+}
+
+// Token kilit her alındığında artan fencing token'dır. Korunan kaynağa yazarken
+// gönderilip daha büyük bir token görülmüşse yazma reddedilmelidir.
+func (l *Lease) Token() int64 {
+	return l.token
+}
+
+// Context kilit kaybedildiğinde veya bırakıldığında iptal edilir
+func (l *Lease) Context() context.Context {
+	return l.ctx
+}
+
+// Release yenilemeyi durdurur ve kilidi bırakır. Birden fazla çağrılabilir.
+func (l *Lease) Release(ctx context.Context) error {
+	l.cancel()
+	<-l.done
+	l.release(ctx)
+	return l.releaseErr
+}
+
+func (l *Lease) release(ctx context.Context) {
+	l.releaseOnce.Do(func() {
+		released, err := l.locker.ReleaseLock(ctx, l.key, l.owner)
+		if err != nil {
+			l.releaseErr = err
+		} else if !released {
+			l.releaseErr = ErrLockLost
+		}
+	})
+}
+
+// Kilidi süresi dolmadan uzatır. Sahiplik kaybedilirse veya son uzatmanın üzerinden
+// ttl'in üçte ikisi geçtiyse (ör. Redis'e erişilemiyorsa) lease iptal edilir; kalan üçte
+// bir, kritik bölgenin durması ve saat farkları için paydır.
+func (l *Lease) keepAlive(parent context.Context, acquiredAt time.Time) {
+	defer close(l.done)
+
+	safeUntil := acquiredAt.Add(l.safeTTL())
+	wait := l.ttl / 3
+	for {
+		select {
+		case <-l.ctx.Done():
+			// Release çağrılmadan ctx iptal edildiyse kilit süresinin dolması beklenmez
+			if parent.Err() != nil {
+				bg, cancel := context.WithTimeout(context.Background(), l.ttl)
+				l.release(bg)
+				cancel()
+			}
+			return
+		case <-time.After(min(wait, time.Until(safeUntil))):
+		}
+
+		// Uzatma isteği de güvenli süreyi aşamaz; takılan bir çağrı lease'i canlı tutmasın
+		start := time.Now()
+		renewCtx, cancel := context.WithDeadline(l.ctx, safeUntil)
+		renewed, err := l.locker.RenewLock(renewCtx, l.key, l.owner, l.ttl)
+		cancel()
+		if err == nil && renewed {
+			safeUntil = start.Add(l.safeTTL())
+			wait = l.ttl / 3
+			continue
+		}
+		if err == nil || !time.Now().Before(safeUntil) {
+			l.cancel()
+			return
+		}
+		wait = l.ttl / lockRenewRetryDivisor
+	}
+}
+
+// Kilidin uzatılmadan güvenle tutulabileceği süre
+func (l *Lease) safeTTL() time.Duration {
+	return l.ttl * 2 / 3
+}
+
+func newLockOwner() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
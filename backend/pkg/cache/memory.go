@@ -0,0 +1,393 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultMemoryMaxEntries = 10000
+
+// MemoryCache tek process içinde tutulan LRU + TTL Store'dur. Kapasite dolunca
+// en uzun süredir kullanılmayan key silinir; süresi dolan key'ler okunurken temizlenir.
+// Birden fazla instance arasında paylaşılmaz (token iptali, OTP vb. için Redis gerekir).
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+	tags       map[string]map[string]struct{} // tag -> key'ler
+
+	// Kilitler LRU'dan ayrı tutulur, kapasite dolunca silinmez
+	locks  map[string]memoryLock
+	fences map[string]int64
+
+	// Rate limit TAT'leri; süresi geçenler periyodik olarak temizlenir
+	rates      map[string]time.Time
+	rateChecks int
+}
+
+type memoryLock struct {
+	owner     string
+	expiresAt time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // sıfır ise süresiz
+	tags      []string
+}
+
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultMemoryMaxEntries
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		tags:       make(map[string]map[string]struct{}),
+		locks:      make(map[string]memoryLock),
+		fences:     make(map[string]int64),
+		rates:      make(map[string]time.Time),
+	}
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	return c.SetWithTags(ctx, key, value, expiration)
+}
+
+// Key tekrar yazılırsa yeni tag'ler eskilerine eklenir (Redis ile aynı)
+func (c *MemoryCache) SetWithTags(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	_, err := c.setWithTags(ctx, key, value, expiration, tags...)
+	return err
+}
+
+func (c *MemoryCache) setWithTags(_ context.Context, key string, value interface{}, expiration time.Duration, tags ...string) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	old := c.lookup(key)
+	replaced := old != nil
+	if replaced {
+		tags = mergeTags(old.Value.(*memoryEntry).tags, tags)
+	}
+	entry := &memoryEntry{key: key, value: data, expiresAt: expiresAt(expiration), tags: tags}
+	if el, ok := c.items[key]; ok {
+		c.untag(el.Value.(*memoryEntry))
+		el.Value = entry
+		c.ll.MoveToFront(el)
+	} else {
+		c.items[key] = c.ll.PushFront(entry)
+	}
+	for _, tag := range tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = make(map[string]struct{})
+		}
+		c.tags[tag][key] = struct{}{}
+	}
+
+	c.evict()
+	return replaced, nil
+}
+
+func (c *MemoryCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lookup(key) != nil {
+		return false, nil
+	}
+	c.items[key] = c.ll.PushFront(&memoryEntry{key: key, value: data, expiresAt: expiresAt(expiration)})
+	c.evict()
+	return true, nil
+}
+
+func (c *MemoryCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	return c.invalidateTags(ctx, nil, tags...)
+}
+
+// onDelete verilirse silinen key'lerle çağrılır (bkz. TieredCache)
+func (c *MemoryCache) invalidateTags(_ context.Context, onDelete func(keys []string), tags ...string) error {
+	c.mu.Lock()
+	var deleted []string
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			if el, ok := c.items[key]; ok {
+				c.removeElement(el)
+				deleted = append(deleted, key)
+			}
+		}
+		delete(c.tags, tag)
+	}
+	c.mu.Unlock()
+
+	if onDelete != nil && len(deleted) > 0 {
+		onDelete(deleted)
+	}
+	return nil
+}
+
+func (c *MemoryCache) Get(_ context.Context, key string, dest interface{}) error {
+	c.mu.Lock()
+	el := c.lookup(key)
+	if el == nil {
+		c.mu.Unlock()
+		return ErrNotFound
+	}
+	c.ll.MoveToFront(el)
+	data := el.Value.(*memoryEntry).value
+	c.mu.Unlock()
+
+	return json.Unmarshal(data, dest)
+}
+
+func (c *MemoryCache) getWithTTL(_ context.Context, key string, dest interface{}) (time.Duration, error) {
+	c.mu.Lock()
+	el := c.lookup(key)
+	if el == nil {
+		c.mu.Unlock()
+		return 0, ErrNotFound
+	}
+	c.ll.MoveToFront(el)
+	entry := el.Value.(*memoryEntry)
+	var ttl time.Duration
+	if !entry.expiresAt.IsZero() {
+		// Süresiz (0) sayılmasın
+		ttl = max(time.Until(entry.expiresAt), time.Nanosecond)
+	}
+	data := entry.value
+	c.mu.Unlock()
+
+	return ttl, json.Unmarshal(data, dest)
+}
+
+func (c *MemoryCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+	return nil
+}
+
+func (c *MemoryCache) DeleteMany(_ context.Context, pattern string) error {
+	re, err := globToRegexp(pattern)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, el := range c.items {
+		if re.MatchString(key) {
+			c.removeElement(el)
+		}
+	}
+	return nil
+}
+
+func (c *MemoryCache) Exists(_ context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lookup(key) != nil, nil
+}
+
+// Redis'teki gibi olmayan key için hata dönmez
+func (c *MemoryCache) Expire(_ context.Context, key string, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el := c.lookup(key); el != nil {
+		el.Value.(*memoryEntry).expiresAt = expiresAt(expiration)
+	}
+	return nil
+}
+
+func (c *MemoryCache) Incr(_ context.Context, key string, expiration time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el := c.lookup(key); el != nil {
+		entry := el.Value.(*memoryEntry)
+		var n int64
+		if err := json.Unmarshal(entry.value, &n); err != nil {
+			return 0, err
+		}
+		n++
+		entry.value = []byte(strconv.FormatInt(n, 10))
+		c.ll.MoveToFront(el)
+		return n, nil
+	}
+
+	c.items[key] = c.ll.PushFront(&memoryEntry{key: key, value: []byte("1"), expiresAt: expiresAt(expiration)})
+	c.evict()
+	return 1, nil
+}
+
+func (c *MemoryCache) AcquireLock(_ context.Context, key, owner string, ttl time.Duration) (int64, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if lock, ok := c.locks[key]; ok && time.Now().Before(lock.expiresAt) {
+		return 0, false, nil
+	}
+	c.locks[key] = memoryLock{owner: owner, expiresAt: time.Now().Add(ttl)}
+	c.fences[key]++
+	return c.fences[key], true, nil
+}
+
+func (c *MemoryCache) RenewLock(_ context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lock, ok := c.locks[key]
+	if !ok || lock.owner != owner || time.Now().After(lock.expiresAt) {
+		return false, nil
+	}
+	lock.expiresAt = time.Now().Add(ttl)
+	c.locks[key] = lock
+	return true, nil
+}
+
+func (c *MemoryCache) ReleaseLock(_ context.Context, key, owner string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lock, ok := c.locks[key]
+	if !ok || lock.owner != owner || time.Now().After(lock.expiresAt) {
+		return false, nil
+	}
+	delete(c.locks, key)
+	return true, nil
+}
+
+// Her bu kadar Allow çağrısında süresi geçmiş TAT'ler silinir
+const memoryRateSweepInterval = 1000
+
+func (c *MemoryCache) Allow(_ context.Context, key string, limit RateLimit) (RateLimitResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	result, tat := gcra(now, c.rates[key], limit)
+	if result.Allowed {
+		c.rates[key] = tat
+	}
+
+	c.rateChecks++
+	if c.rateChecks%memoryRateSweepInterval == 0 {
+		for k, t := range c.rates {
+			if t.Before(now) {
+				delete(c.rates, k)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (c *MemoryCache) Close() error {
+	return nil
+}
+
+// Len cache'teki (süresi dolmuş ama henüz temizlenmemişler dahil) key sayısını döner
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+// Süresi dolmuş key'i siler ve nil döner; çağıran mu'yu tutmalıdır
+func (c *MemoryCache) lookup(key string) *list.Element {
+	el, ok := c.items[key]
+	if !ok {
+		return nil
+	}
+	entry := el.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.removeElement(el)
+		return nil
+	}
+	return el
+}
+
+func (c *MemoryCache) removeElement(el *list.Element) {
+	entry := el.Value.(*memoryEntry)
+	c.untag(entry)
+	c.ll.Remove(el)
+	delete(c.items, entry.key)
+}
+
+// Kapasite aşıldıysa en uzun süredir kullanılmayan key'leri siler
+func (c *MemoryCache) evict() {
+	for c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// Key'i tag index'inden çıkarır; boş kalan tag silinir
+func (c *MemoryCache) untag(entry *memoryEntry) {
+	for _, tag := range entry.tags {
+		delete(c.tags[tag], entry.key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
+
+// Yeni tag'leri tekrarsız olarak eskilerin sonuna ekler
+func mergeTags(existing, added []string) []string {
+	merged := slices.Clone(existing)
+	for _, tag := range added {
+		if !slices.Contains(merged, tag) {
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
+
+func expiresAt(expiration time.Duration) time.Time {
+	if expiration <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(expiration)
+}
+
+// Redis glob pattern'ini (* ve ?) regexp'e çevirir
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// NoopCache hiçbir şey saklamaz; her okuma cache miss'tir. Cache'i tamamen
+// kapatmak veya testlerde dış bağımlılık olmadan çalışmak için kullanılır.
+type NoopCache struct{}
+
+func NewNoopCache() *NoopCache {
+	return &NoopCache{}
+}
+
+func (NoopCache) Set(context.Context, string, interface{}, time.Duration) error { return nil }
+func (NoopCache) SetNX(context.Context, string, interface{}, time.Duration) (bool, error) {
+	return true, nil
+}
+func (NoopCache) Get(context.Context, string, interface{}) error      { return ErrNotFound }
+func (NoopCache) Delete(context.Context, string) error                { return nil }
+func (NoopCache) DeleteMany(context.Context, string) error            { return nil }
+func (NoopCache) Exists(context.Context, string) (bool, error)        { return false, nil }
+func (NoopCache) Expire(context.Context, string, time.Duration) error { return nil }
+func (NoopCache) Incr(context.Context, string, time.Duration) (int64, error) {
+	return 1, nil
+}
+func (NoopCache) SetWithTags(context.Context, string, interface{}, time.Duration, ...string) error {
+	return nil
+}
+func (NoopCache) InvalidateTags(context.Context, ...string) error { return nil }
+func (NoopCache) Close() error                                    { return nil }
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+const rateLimitKeyPrefix = "ratelimit:"
+
+// RateLimit period içinde en fazla Limit isteğe izin verir. İstekler period/Limit
+// aralıklarla yenilenir; boştaki bir istemci Limit kadar isteği art arda yapabilir.
+type RateLimit struct {
+	Limit  int
+	Period time.Duration
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int           // Şu an art arda yapılabilecek istek sayısı
+	RetryAfter time.Duration // Reddedildiyse bir sonraki isteğe izin verilene kadar geçecek süre
+	ResetAfter time.Duration // Kotanın tamamen dolmasına kalan süre
+}
+
+// RateLimiter GCRA (token bucket'a denk) ile istek sınırlar. Her key için sadece
+// bir sonraki isteğin "teorik varış zamanı" (TAT) tutulur.
+// RedisCache, MemoryCache ve TieredCache destekler.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error)
+}
+
+// gcra TAT'e göre isteği değerlendirir ve yeni TAT'i döner. Redis'teki Lua
+// script'i aynı hesabı milisaniye hassasiyetinde yapar.
+func gcra(now, tat time.Time, limit RateLimit) (RateLimitResult, time.Time) {
+	interval := limit.Period / time.Duration(limit.Limit)
+	burst := interval * time.Duration(limit.Limit)
+	if tat.Before(now) {
+		tat = now
+	}
+
+	newTAT := tat.Add(interval)
+	diff := now.Sub(newTAT.Add(-burst))
+	if diff < 0 {
+		return RateLimitResult{RetryAfter: -diff, ResetAfter: tat.Sub(now)}, tat
+	}
+	return RateLimitResult{Allowed: true, Remaining: int(diff / interval), ResetAfter: newTAT.Sub(now)}, newTAT
+}
@@ -2,52 +2,189 @@ package cache
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"github.com/Furkanturan8/goftr-template/config"
 	"github.com/Furkanturan8/goftr-template/pkg/logger"
 	"github.com/redis/go-redis/v9"
+	"os"
+	"strings"
 	"time"
 )
 
+const (
+	// SCAN'in her adımda taradığı yaklaşık key sayısı ve tek pipeline'da silinen key sayısı
+	redisScanCount   = 1000
+	redisDeleteBatch = 500
+)
+
+// Kilit alma, uzatma ve bırakma atomik olmalı (kontrol ve yazma arasında kilit el değiştirebilir).
+// Kilit ve fencing sayacı aynı hash tag'i taşır, cluster'da aynı slot'a düşer.
+var (
+	acquireLockScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0`)
+
+	renewLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+	// Key'i yazar ve önceden var olup olmadığını döner (bkz. TieredCache)
+	setReportingScript = redis.NewScript(`
+local existed = redis.call("EXISTS", KEYS[1])
+if tonumber(ARGV[2]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+else
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+return existed`)
+
+	// GCRA (bkz. gcra); zaman instance saatlerinden bağımsız olsun diye Redis'ten alınır.
+	// Dönüş: {izin, kalan, retry_after_ms, reset_after_ms}
+	rateLimitScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local tat = tonumber(redis.call("GET", KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+local new_tat = tat + interval
+local diff = now - (new_tat - burst)
+if diff < 0 then
+	return {0, 0, -diff, tat - now}
+end
+redis.call("SET", KEYS[1], new_tat, "PX", new_tat - now)
+return {1, math.floor(diff / interval), 0, new_tat - now}`)
+)
+
+// Desteklenen Redis topolojileri (REDIS_MODE)
+const (
+	RedisModeStandalone = "standalone"
+	RedisModeSentinel   = "sentinel"
+	RedisModeCluster    = "cluster"
+)
+
+const (
+	redisDialTimeout     = 5 * time.Second
+	redisMaxRetryBackoff = 5 * time.Second
+)
+
+// RedisCache birden fazla uygulama instance'ı arasında paylaşılan Store'dur.
+// Client tek node, Sentinel veya Cluster olabilir (redis.UniversalClient).
 type RedisCache struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
-var defaultCache *RedisCache
+func NewRedisCache(cfg *config.RedisConfig) (*RedisCache, error) {
+	opts, err := RedisOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-func NewRedisCache(addr, password string, db int) (*RedisCache, error) {
-	logger.Info("Redis bağlantısı başlatılıyor: %s", addr)
+	mode := cfg.Mode
+	if mode == "" {
+		mode = RedisModeStandalone
+	}
+	logger.Info("Redis bağlantısı başlatılıyor (%s): %s", mode, strings.Join(opts.Addrs, ","))
+	return &RedisCache{client: redis.NewUniversalClient(opts)}, nil
+}
 
-	client := redis.NewClient(&redis.Options{
-		Addr:            addr,
-		Password:        password,
-		DB:              db,
-		MaxRetries:      5,
-		MaxRetryBackoff: 5 * time.Second,
-		MinRetryBackoff: time.Second * 2,
-		DialTimeout:     time.Second * 5,
-	})
+// Ping Redis'e ulaşılabildiğini kontrol eder. Ulaşılamasa da client kullanılabilir;
+// go-redis bağlantıyı kendisi yeniden kurar, o zamana kadar her işlem hata döner.
+func (c *RedisCache) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
 
-	// Bağlantıyı test et
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// RedisOptions yapılandırmadan redis.UniversalClient seçeneklerini üretir.
+// Sentinel için MasterName, cluster için IsClusterMode ayarlanır; go-redis
+// client tipini bunlara göre seçer.
+func RedisOptions(cfg *config.RedisConfig) (*redis.UniversalOptions, error) {
+	opts := &redis.UniversalOptions{
+		Addrs:           cfg.GetAddrs(),
+		Username:        cfg.Username,
+		Password:        cfg.Password,
+		DB:              cfg.DB,
+		PoolSize:        cfg.PoolSize,
+		MinIdleConns:    cfg.MinIdleConns,
+		MaxRetries:      cfg.MaxRetries,
+		MinRetryBackoff: time.Duration(cfg.RetryInterval) * time.Millisecond,
+		MaxRetryBackoff: redisMaxRetryBackoff,
+		DialTimeout:     redisDialTimeout,
+	}
 
-	if err := client.Ping(ctx).Err(); err != nil {
-		logger.Error("Redis ping hatası: %v", err)
-		return nil, fmt.Errorf("redis ping hatası: %v", err)
+	switch cfg.Mode {
+	case RedisModeStandalone, "":
+		if len(opts.Addrs) > 1 {
+			return nil, fmt.Errorf("redis standalone modunda tek adres verilmeli, %d verildi", len(opts.Addrs))
+		}
+	case RedisModeSentinel:
+		if cfg.MasterName == "" {
+			return nil, errors.New("redis sentinel modu için REDIS_MASTER_NAME gerekli")
+		}
+		opts.MasterName = cfg.MasterName
+		opts.SentinelUsername = cfg.SentinelUsername
+		opts.SentinelPassword = cfg.SentinelPassword
+	case RedisModeCluster:
+		if cfg.DB != 0 {
+			return nil, errors.New("redis cluster sadece 0 numaralı veritabanını destekler")
+		}
+		opts.IsClusterMode = true
+	default:
+		return nil, fmt.Errorf("bilinmeyen redis modu: %q", cfg.Mode)
 	}
 
-	return &RedisCache{client: client}, nil
+	if cfg.TLS.Enabled {
+		tlsConfig, err := redisTLSConfig(&cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		opts.TLSConfig = tlsConfig
+	}
+	return opts, nil
 }
 
-func InitDefaultCache(addr, password string, db int) error {
-	cache, err := NewRedisCache(addr, password, db)
-	if err != nil {
-		return err
+func redisTLSConfig(cfg *config.RedisTLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
 	}
-	defaultCache = cache
-	return nil
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("redis CA dosyası okunamadı: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("redis CA dosyasında geçerli sertifika yok: %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("redis client sertifikası yüklenemedi: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
 }
 
 // Veriyi JSON olarak cache'e yazar
@@ -59,28 +196,156 @@ func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, exp
 	return c.client.Set(ctx, key, json, expiration).Err()
 }
 
+func (c *RedisCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	return c.client.SetNX(ctx, key, data, expiration).Result()
+}
+
 // Cache'den veriyi okur ve verilen struct'a unmarshal eder
 func (c *RedisCache) Get(ctx context.Context, key string, dest interface{}) error {
 	val, err := c.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return ErrNotFound
+	}
 	if err != nil {
 		return err
 	}
 	return json.Unmarshal([]byte(val), dest)
 }
 
+// Değerle birlikte kalan süresini de okur; key süresizse süre 0'dır
+func (c *RedisCache) getWithTTL(ctx context.Context, key string, dest interface{}) (time.Duration, error) {
+	var (
+		get  *redis.StringCmd
+		pttl *redis.DurationCmd
+	)
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pttl = pipe.PTTL(ctx, key)
+		return nil
+	})
+	if errors.Is(get.Err(), redis.Nil) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// PTTL süresiz key için -1 döner; süresi dolmak üzere olan key süresiz sayılmasın
+	ttl := pttl.Val()
+	if ttl < 0 {
+		ttl = 0
+	} else if ttl == 0 {
+		ttl = time.Nanosecond
+	}
+	return ttl, json.Unmarshal([]byte(get.Val()), dest)
+}
+
 // Cache'den veriyi siler
 func (c *RedisCache) Delete(ctx context.Context, key string) error {
 	return c.client.Del(ctx, key).Err()
 }
 
-// Birden fazla key'i siler
+// Pattern'e uyan key'leri siler. KEYS Redis'i tüm keyspace taranana kadar
+// bloklayacağı için SCAN ile parça parça okunur ve UNLINK ile (arka planda) silinir.
+// Cluster'da SCAN sadece bağlanılan node'u taradığı için her master ayrı taranır.
 func (c *RedisCache) DeleteMany(ctx context.Context, pattern string) error {
-	keys, err := c.client.Keys(ctx, pattern).Result()
-	if err != nil {
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return c.deleteMatching(ctx, node, pattern)
+		})
+	}
+	return c.deleteMatching(ctx, c.client, pattern)
+}
+
+func (c *RedisCache) deleteMatching(ctx context.Context, node redis.Cmdable, pattern string) error {
+	iter := node.Scan(ctx, 0, pattern, redisScanCount).Iterator()
+	batch := make([]string, 0, redisDeleteBatch)
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == redisDeleteBatch {
+			if err := c.unlink(ctx, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
 		return err
 	}
-	if len(keys) > 0 {
-		return c.client.Del(ctx, keys...).Err()
+	return c.unlink(ctx, batch)
+}
+
+// SetWithTags değeri yazar ve key'i her tag'in set'ine ekler. Tag set'i en uzun
+// ömürlü üyesi kadar yaşar; expiration 0 ise tag set'i de süresiz olur.
+// ExpireNX/ExpireGT Redis 7 gerektirir.
+func (c *RedisCache) SetWithTags(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	_, err := c.setWithTags(ctx, key, value, expiration, tags...)
+	return err
+}
+
+// SetWithTags gibi yazar; key önceden varsa replaced true döner
+func (c *RedisCache) setWithTags(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	ms := expiration.Milliseconds()
+	if expiration > 0 && ms == 0 {
+		ms = 1
+	}
+
+	// Tag set'leri farklı slot'larda olabileceği için MULTI yerine düz pipeline kullanılır
+	var set *redis.Cmd
+	_, err = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		set = setReportingScript.Eval(ctx, pipe, []string{key}, data, ms)
+		for _, tag := range tags {
+			tk := tagKey(tag)
+			pipe.SAdd(ctx, tk, key)
+			if expiration > 0 {
+				pipe.ExpireNX(ctx, tk, expiration)
+				pipe.ExpireGT(ctx, tk, expiration)
+			} else {
+				pipe.Persist(ctx, tk)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	existed, err := set.Int64()
+	return existed == 1, err
+}
+
+// InvalidateTags tag'lerden herhangi birini taşıyan tüm key'leri siler.
+// Üyeler SPOP ile alındığı için silme sırasında tag'e eklenen key'ler de silinir.
+func (c *RedisCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	return c.invalidateTags(ctx, nil, tags...)
+}
+
+// onDelete verilirse silinen her key grubu ile çağrılır (bkz. TieredCache)
+func (c *RedisCache) invalidateTags(ctx context.Context, onDelete func(keys []string), tags ...string) error {
+	for _, tag := range tags {
+		tk := tagKey(tag)
+		for {
+			keys, err := c.client.SPopN(ctx, tk, redisDeleteBatch).Result()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return err
+			}
+			if len(keys) == 0 {
+				break
+			}
+			if err := c.unlink(ctx, keys); err != nil {
+				return err
+			}
+			if onDelete != nil {
+				onDelete(keys)
+			}
+		}
 	}
 	return nil
 }
@@ -96,45 +361,80 @@ func (c *RedisCache) Expire(ctx context.Context, key string, expiration time.Dur
 	return c.client.Expire(ctx, key, expiration).Err()
 }
 
-// Global fonksiyonlar
-func Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	if defaultCache == nil {
-		return errorx.WrapMsg(errorx.ErrInternal, "Redis cache başlatılmadı: Set işlemi gerçekleştirilemedi")
+// Sayaç ve süresi tek key üzerinde olduğu için MULTI cluster'da da çalışır
+func (c *RedisCache) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		if expiration > 0 {
+			pipe.ExpireNX(ctx, key, expiration)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
-	return defaultCache.Set(ctx, key, value, expiration)
+	return incr.Val(), nil
 }
 
-func Get(ctx context.Context, key string, dest interface{}) error {
-	if defaultCache == nil {
-		return errorx.WrapMsg(errorx.ErrInternal, "Redis cache başlatılmadı: Get işlemi gerçekleştirilemedi")
+func (c *RedisCache) AcquireLock(ctx context.Context, key, owner string, ttl time.Duration) (int64, bool, error) {
+	lockKey, fenceKey := redisLockKeys(key)
+	token, err := acquireLockScript.Run(ctx, c.client, []string{lockKey, fenceKey}, owner, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, false, err
 	}
-	return defaultCache.Get(ctx, key, dest)
+	return token, token > 0, nil
 }
 
-func Delete(ctx context.Context, key string) error {
-	if defaultCache == nil {
-		return errorx.WrapMsg(errorx.ErrInternal, "Redis cache başlatılmadı: Delete işlemi gerçekleştirilemedi")
-	}
-	return defaultCache.Delete(ctx, key)
+func (c *RedisCache) RenewLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	lockKey, _ := redisLockKeys(key)
+	n, err := renewLockScript.Run(ctx, c.client, []string{lockKey}, owner, ttl.Milliseconds()).Int64()
+	return n == 1, err
 }
 
-func DeleteMany(ctx context.Context, pattern string) error {
-	if defaultCache == nil {
-		return errorx.WrapMsg(errorx.ErrInternal, "Redis cache başlatılmadı: DeleteMany işlemi gerçekleştirilemedi")
-	}
-	return defaultCache.DeleteMany(ctx, pattern)
+func (c *RedisCache) ReleaseLock(ctx context.Context, key, owner string) (bool, error) {
+	lockKey, _ := redisLockKeys(key)
+	n, err := releaseLockScript.Run(ctx, c.client, []string{lockKey}, owner).Int64()
+	return n == 1, err
 }
 
-func Exists(ctx context.Context, key string) (bool, error) {
-	if defaultCache == nil {
-		return false, errorx.WrapMsg(errorx.ErrInternal, "Redis cache başlatılmadı: Exists işlemi gerçekleştirilemedi")
+func (c *RedisCache) Allow(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error) {
+	interval := (limit.Period / time.Duration(limit.Limit)).Milliseconds()
+	if interval < 1 {
+		interval = 1
 	}
-	return defaultCache.Exists(ctx, key)
+	res, err := rateLimitScript.Run(ctx, c.client, []string{rateLimitKeyPrefix + key}, interval, interval*int64(limit.Limit)).Int64Slice()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	return RateLimitResult{
+		Allowed:    res[0] == 1,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+		ResetAfter: time.Duration(res[3]) * time.Millisecond,
+	}, nil
 }
 
-func Expire(ctx context.Context, key string, expiration time.Duration) error {
-	if defaultCache == nil {
-		return errorx.WrapMsg(errorx.ErrInternal, "Redis cache başlatılmadı: Expire işlemi gerçekleştirilemedi")
+// lock:{key} ve lock:{key}:fence
+func redisLockKeys(key string) (string, string) {
+	lockKey := lockKeyPrefix + "{" + key + "}"
+	return lockKey, lockKey + ":fence"
+}
+
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+func (c *RedisCache) unlink(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
 	}
-	return defaultCache.Expire(ctx, key, expiration)
+	// Cluster'da key'ler farklı slot'larda olabilir, her key ayrı komutla silinir
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Unlink(ctx, key)
+		}
+		return nil
+	})
+	return err
 }
@@ -0,0 +1,363 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// Invalidation mesajlarının yayınlandığı Redis kanalı
+	invalidationChannel = "cache:invalidate"
+	// Key'ler bu kadar generation sayacına dağıtılır; bellek kullanımı key sayısından bağımsızdır
+	invalidationStripes = 256
+)
+
+var (
+	metricsOnce   sync.Once
+	cacheRequests *prometheus.CounterVec
+)
+
+// Prometheus'a cache_requests_total{tier, result} sayaçlarını bir kez kaydeder
+func initMetrics() {
+	metricsOnce.Do(func() {
+		cacheRequests = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "cache_requests_total",
+				Help: "Katman bazında cache okuma sayısı",
+			},
+			[]string{"tier", "result"},
+		)
+		prometheus.MustRegister(cacheRequests)
+	})
+}
+
+// TierStats katman bazında hit/miss sayılarıdır
+type TierStats struct {
+	L1Hits   int64 `json:"l1_hits"`
+	L1Misses int64 `json:"l1_misses"`
+	L2Hits   int64 `json:"l2_hits"`
+	L2Misses int64 `json:"l2_misses"`
+}
+
+// Broadcaster invalidation mesajlarını diğer instance'lara iletir (Redis'te pub/sub)
+type Broadcaster interface {
+	Publish(ctx context.Context, msg []byte) error
+	// Subscribe gelen mesajları handler ile işler; dönen fonksiyon aboneliği kapatır
+	Subscribe(handler func(msg []byte)) (stop func())
+}
+
+// tierStore TieredCache'in L2 katmanından beklediği işlemlerdir; RedisCache ve MemoryCache uyar
+type tierStore interface {
+	Store
+	Locker
+	RateLimiter
+	getWithTTL(ctx context.Context, key string, dest interface{}) (time.Duration, error)
+	setWithTags(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) (replaced bool, err error)
+	invalidateTags(ctx context.Context, onDelete func(keys []string), tags ...string) error
+}
+
+// TieredCache Redis (L2) önünde process içi bir L1 tutar. Okumalar önce L1'e bakar,
+// yazma ve silmeler Redis'e yapılıp diğer instance'lara duyurulur; her instance kendi
+// L1'indeki kopyayı siler. L1 kopyası Redis'teki key'den uzun yaşamaz, bu yüzden
+// daha önce olmayan bir key'in yazılması (cache doldurma) duyurulmaz. Mesaj kaçarsa
+// (ör. Redis bağlantısı koparsa) L1'deki eski değer en fazla l1TTL kadar yaşar.
+type TieredCache struct {
+	l1     *MemoryCache
+	l2     tierStore
+	l1TTL  time.Duration
+	origin string // Kendi mesajlarımızı ayırt etmek için instance id'si
+
+	bus  Broadcaster
+	stop func()
+
+	// Invalidation'da artan sayaçlar; okuma sırasında değişirse L1'e eski değer yazılmaz
+	generations [invalidationStripes]atomic.Uint64
+
+	l1Hits, l1Misses, l2Hits, l2Misses atomic.Int64
+}
+
+type invalidation struct {
+	Origin   string   `json:"o"`
+	Keys     []string `json:"k,omitempty"`
+	Patterns []string `json:"p,omitempty"`
+}
+
+func NewTieredCache(l1 *MemoryCache, l2 tierStore, bus Broadcaster, l1TTL time.Duration) (*TieredCache, error) {
+	initMetrics()
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	c := &TieredCache{l1: l1, l2: l2, l1TTL: l1TTL, origin: hex.EncodeToString(id), bus: bus}
+	c.stop = bus.Subscribe(c.apply)
+
+	logger.Info("L1 cache aktif (en fazla %d kayıt, TTL %s)", l1.maxEntries, l1TTL)
+	return c, nil
+}
+
+func (c *TieredCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	return c.SetWithTags(ctx, key, value, expiration)
+}
+
+// Tag'ler sadece L2'de tutulur; L1 girdileri tag invalidation'da key bazında silinir.
+// Key önceden yoksa başka bir instance'ın L1'inde de olamaz, duyurulmaz.
+func (c *TieredCache) SetWithTags(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	replaced, err := c.l2.setWithTags(ctx, key, json.RawMessage(data), expiration, tags...)
+	if err != nil {
+		return err
+	}
+	c.invalidated(key)
+	if replaced {
+		c.publish(ctx, invalidation{Keys: []string{key}})
+	}
+	return c.l1.Set(ctx, key, json.RawMessage(data), c.l1Expiration(expiration))
+}
+
+// Kilitler instance'lar arasında olduğu için sadece Redis'te tutulur
+func (c *TieredCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return c.l2.SetNX(ctx, key, value, expiration)
+}
+
+// Kilitler sadece Redis'te tutulur
+func (c *TieredCache) AcquireLock(ctx context.Context, key, owner string, ttl time.Duration) (int64, bool, error) {
+	return c.l2.AcquireLock(ctx, key, owner, ttl)
+}
+
+func (c *TieredCache) RenewLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	return c.l2.RenewLock(ctx, key, owner, ttl)
+}
+
+func (c *TieredCache) ReleaseLock(ctx context.Context, key, owner string) (bool, error) {
+	return c.l2.ReleaseLock(ctx, key, owner)
+}
+
+// Limitler instance'lar arasında paylaşılır, sadece Redis'te tutulur
+func (c *TieredCache) Allow(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error) {
+	return c.l2.Allow(ctx, key, limit)
+}
+
+// L2'den okunan değer L1'e Redis'teki kalan süresinden uzun yazılmaz. Okuma sırasında
+// key için invalidation geldiyse okunan değer eski olabileceği için L1'de bırakılmaz.
+func (c *TieredCache) Get(ctx context.Context, key string, dest interface{}) error {
+	var raw json.RawMessage
+	if err := c.l1.Get(ctx, key, &raw); err == nil {
+		c.count(&c.l1Hits, "l1", "hit")
+		return json.Unmarshal(raw, dest)
+	}
+	c.count(&c.l1Misses, "l1", "miss")
+
+	gen := c.generation(key)
+	before := gen.Load()
+	ttl, err := c.l2.getWithTTL(ctx, key, &raw)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			c.count(&c.l2Misses, "l2", "miss")
+		}
+		return err
+	}
+	c.count(&c.l2Hits, "l2", "hit")
+
+	if gen.Load() == before {
+		_ = c.l1.Set(ctx, key, raw, c.l1Expiration(ttl))
+		// Yazma ile kontrol arasında invalidation geldiyse silen taraf bizden önce silmiş olabilir
+		if gen.Load() != before {
+			_ = c.l1.Delete(ctx, key)
+		}
+	}
+	return json.Unmarshal(raw, dest)
+}
+
+// L1 her zaman L2'den sonra silinir; arada L2'den okuyan bir Get eski değeri L1'e geri yazamaz
+func (c *TieredCache) Delete(ctx context.Context, key string) error {
+	err := c.l2.Delete(ctx, key)
+	c.invalidated(key)
+	_ = c.l1.Delete(ctx, key)
+	if err != nil {
+		return err
+	}
+	c.publish(ctx, invalidation{Keys: []string{key}})
+	return nil
+}
+
+func (c *TieredCache) DeleteMany(ctx context.Context, pattern string) error {
+	err := c.l2.DeleteMany(ctx, pattern)
+	c.invalidatedAll()
+	_ = c.l1.DeleteMany(ctx, pattern)
+	if err != nil {
+		return err
+	}
+	c.publish(ctx, invalidation{Patterns: []string{pattern}})
+	return nil
+}
+
+// L2'de silinen key'ler parça parça yayınlanır
+func (c *TieredCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	return c.l2.invalidateTags(ctx, func(keys []string) {
+		c.invalidated(keys...)
+		for _, key := range keys {
+			_ = c.l1.Delete(ctx, key)
+		}
+		c.publish(ctx, invalidation{Keys: keys})
+	}, tags...)
+}
+
+func (c *TieredCache) Exists(ctx context.Context, key string) (bool, error) {
+	if ok, _ := c.l1.Exists(ctx, key); ok {
+		return true, nil
+	}
+	return c.l2.Exists(ctx, key)
+}
+
+func (c *TieredCache) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	if err := c.l2.Expire(ctx, key, expiration); err != nil {
+		return err
+	}
+	c.invalidated(key)
+	_ = c.l1.Delete(ctx, key)
+	c.publish(ctx, invalidation{Keys: []string{key}})
+	return nil
+}
+
+// Sayaçlar sadece L2'de artırılır
+func (c *TieredCache) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	n, err := c.l2.Incr(ctx, key, expiration)
+	if err != nil {
+		return 0, err
+	}
+	c.invalidated(key)
+	_ = c.l1.Delete(ctx, key)
+	c.publish(ctx, invalidation{Keys: []string{key}})
+	return n, nil
+}
+
+// Stats başlangıçtan beri katman bazında hit/miss sayılarını döner
+func (c *TieredCache) Stats() TierStats {
+	return TierStats{
+		L1Hits:   c.l1Hits.Load(),
+		L1Misses: c.l1Misses.Load(),
+		L2Hits:   c.l2Hits.Load(),
+		L2Misses: c.l2Misses.Load(),
+	}
+}
+
+func (c *TieredCache) Close() error {
+	c.stop()
+	return c.l2.Close()
+}
+
+// Yayın hatası işlemi engellemez; diğer instance'lar eski değeri en fazla L1 TTL kadar görür
+func (c *TieredCache) publish(ctx context.Context, msg invalidation) {
+	msg.Origin = c.origin
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	if err := c.bus.Publish(ctx, data); err != nil {
+		logger.Error("Cache invalidation yayınlanamadı: %v", err)
+	}
+}
+
+// Diğer instance'lardan gelen invalidation'ı L1'e uygular
+func (c *TieredCache) apply(payload []byte) {
+	var msg invalidation
+	if err := json.Unmarshal(payload, &msg); err != nil || msg.Origin == c.origin {
+		return
+	}
+	ctx := context.Background()
+	c.invalidated(msg.Keys...)
+	for _, key := range msg.Keys {
+		_ = c.l1.Delete(ctx, key)
+	}
+	if len(msg.Patterns) > 0 {
+		c.invalidatedAll()
+	}
+	for _, pattern := range msg.Patterns {
+		_ = c.l1.DeleteMany(ctx, pattern)
+	}
+}
+
+func (c *TieredCache) generation(key string) *atomic.Uint64 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return &c.generations[h.Sum32()%invalidationStripes]
+}
+
+// L2 değiştikten sonra, L1'den silmeden önce çağrılır; devam eden okumalar sonucu L1'e yazmaz
+func (c *TieredCache) invalidated(keys ...string) {
+	for _, key := range keys {
+		c.generation(key).Add(1)
+	}
+}
+
+// Pattern silmelerinde hangi key'lerin etkilendiği bilinmez, tüm sayaçlar artırılır
+func (c *TieredCache) invalidatedAll() {
+	for i := range c.generations {
+		c.generations[i].Add(1)
+	}
+}
+
+// L1 süresi Redis'teki süreden uzun olamaz
+func (c *TieredCache) l1Expiration(expiration time.Duration) time.Duration {
+	if expiration > 0 && expiration < c.l1TTL {
+		return expiration
+	}
+	return c.l1TTL
+}
+
+func (c *TieredCache) count(counter *atomic.Int64, tier, result string) {
+	counter.Add(1)
+	cacheRequests.WithLabelValues(tier, result).Inc()
+}
+
+// redisBroadcaster invalidation mesajlarını Redis pub/sub kanalı ile taşır
+type redisBroadcaster struct {
+	client  redis.UniversalClient
+	channel string
+}
+
+// Broadcaster channel üzerinden yayın yapan bir Broadcaster döner
+func (c *RedisCache) Broadcaster(channel string) Broadcaster {
+	return &redisBroadcaster{client: c.client, channel: channel}
+}
+
+func (b *redisBroadcaster) Publish(ctx context.Context, msg []byte) error {
+	return b.client.Publish(ctx, b.channel, msg).Err()
+}
+
+// Bağlantı koparsa go-redis yeniden abone olur
+func (b *redisBroadcaster) Subscribe(handler func(msg []byte)) func() {
+	ps := b.client.Subscribe(context.Background(), b.channel)
+	// Abonelik kesinleşmeden yapılan yazmaların mesajları kaçmasın. Redis'e ulaşılamıyorsa
+	// bağlantı gelince abone olunur; o zamana kadar L1 en fazla l1TTL bayatlar.
+	if _, err := ps.Receive(context.Background()); err != nil {
+		logger.Error("Cache invalidation kanalına abone olunamadı: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msg := range ps.Channel() {
+			handler([]byte(msg.Payload))
+		}
+	}()
+	return func() {
+		_ = ps.Close()
+		<-done
+	}
+}
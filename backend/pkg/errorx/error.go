@@ -14,12 +14,16 @@ var (
 	ErrDuplicate          = New(http.StatusConflict, "Kaynak zaten mevcut")
 	ErrInvalidRequest     = New(http.StatusBadRequest, "Geçersiz istek")
 	ErrInvalidCredentials = New(http.StatusUnauthorized, "Geçersiz kimlik bilgileri")
+	ErrPreconditionFailed = New(http.StatusPreconditionFailed, "Kaynak siz okuduktan sonra değiştirilmiş")
+	ErrPreconditionReq    = New(http.StatusPreconditionRequired, "Bu işlem için If-Match başlığı gerekli")
+	ErrTooManyRequests    = New(http.StatusTooManyRequests, "Çok fazla istek")
 )
 
 type AppError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Err     error  `json:"-"`
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Err     error       `json:"-"`
+	Details interface{} `json:"details,omitempty"` // Alan/kural bazlı hata detayları
 }
 
 func (e *AppError) Error() string {
@@ -67,3 +71,12 @@ func Wrap(base *AppError, err error, customMessage string) *AppError {
 		Err:     err,
 	}
 }
+
+// WithDetails hatayı istemciye dönecek yapılandırılmış detaylarla birlikte oluşturur
+func WithDetails(base *AppError, customMessage string, details interface{}) *AppError {
+	return &AppError{
+		Code:    base.Code,
+		Message: customMessage,
+		Details: details,
+	}
+}
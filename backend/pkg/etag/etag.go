@@ -0,0 +1,62 @@
+package etag
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Format kayıt versiyonundan güçlü (strong) bir ETag üretir: "3"
+func Format(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// Parse ETag değerinden versiyonu çıkarır. W/ ön eki yok sayılır.
+func Parse(tag string) (int64, bool) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+// Match If-None-Match başlığının verilen ETag ile zayıf (weak) karşılaştırmayla
+// eşleşip eşleşmediğini kontrol eder; W/ ön eki yok sayılır.
+// Başlık "*" veya virgülle ayrılmış liste olabilir.
+func Match(header, tag string) bool {
+	return match(header, tag, false)
+}
+
+// MatchStrong If-Match başlığını güçlü (strong) karşılaştırmayla kontrol eder
+// (RFC 9110 13.1.1): W/ ile işaretli ETag'ler hiçbir zaman eşleşmez.
+func MatchStrong(header, tag string) bool {
+	return match(header, tag, true)
+}
+
+func match(header, tag string, strong bool) bool {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+	if strong && strings.HasPrefix(tag, "W/") {
+		return false
+	}
+
+	want := strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strong && strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+		if strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
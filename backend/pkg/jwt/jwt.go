@@ -33,6 +33,8 @@ type Claims struct {
 	Role   model.Role   `json:"role"`
 	Email  string       `json:"email"`
 	Status model.Status `json:"status"`
+	// iat saniye hassasiyetindedir; iptal kontrolü için üretim zamanı milisaniye olarak da tutulur
+	IssuedAtMs int64 `json:"iat_ms,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -54,14 +56,16 @@ func Init(cfg *config.JWTConfig) {
 }
 
 func Generate(user *model.User) (string, error) {
+	now := time.Now()
 	claims := Claims{
 		user.ID,
 		user.Role,
 		user.Email,
 		user.Status,
+		now.UnixMilli(),
 		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(jwtConfig.Expiration) * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(jwtConfig.Expiration) * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
@@ -175,6 +179,101 @@ func ValidatePasswordResetToken(tokenString string) (*PasswordResetClaims, error
 	return nil, jwt.ErrSignatureInvalid
 }
 
+// E-posta değişikliği token amaçları. Onay token'ı geri alma için (veya tersi) kullanılamaz.
+const (
+	EmailChangeConfirm = "confirm"
+	EmailChangeRevert  = "revert"
+)
+
+// EmailChangeClaims yapısı
+type EmailChangeClaims struct {
+	UserID   int64  `json:"user_id"`
+	OldEmail string `json:"old_email"`
+	NewEmail string `json:"new_email"`
+	Purpose  string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+// E-posta değişikliği token'ları access token olarak kabul edilmesin diye ayrı anahtarla imzalanır
+func emailChangeKey() []byte {
+	return []byte(jwtConfig.Secret + ":email-change")
+}
+
+func GenerateEmailChangeToken(userID int64, oldEmail, newEmail, purpose string, ttl time.Duration) (string, error) {
+	claims := EmailChangeClaims{
+		UserID:   userID,
+		OldEmail: oldEmail,
+		NewEmail: newEmail,
+		Purpose:  purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(emailChangeKey())
+}
+
+func ValidateEmailChangeToken(tokenString, purpose string) (*EmailChangeClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &EmailChangeClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return emailChangeKey(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*EmailChangeClaims)
+	if !ok || !token.Valid || claims.Purpose != purpose {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// TwoFactorClaims şifresi doğrulanmış, SMS kodu bekleyen giriş denemesidir
+type TwoFactorClaims struct {
+	UserID int64 `json:"user_id"`
+	jwt.RegisteredClaims
+}
+
+func twoFactorKey() []byte {
+	return []byte(jwtConfig.Secret + ":two-factor")
+}
+
+func GenerateTwoFactorToken(userID int64, ttl time.Duration) (string, error) {
+	claims := TwoFactorClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(twoFactorKey())
+}
+
+func ValidateTwoFactorToken(tokenString string) (*TwoFactorClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &TwoFactorClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return twoFactorKey(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*TwoFactorClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
 // Session yönetimi için in-memory map (production'da Redis kullanılmalı)
 var sessions = make(map[string]*Session)
 
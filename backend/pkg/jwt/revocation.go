@@ -0,0 +1,47 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/pkg/cache"
+	"time"
+)
+
+var ErrTokenRevoked = errors.New("token revoked")
+
+const validAfterKeyPrefix = "token_valid_after:"
+
+// RevokeUserTokens kullanıcının şu ana kadar üretilmiş tüm access token'larını geçersiz kılar.
+// Varsayılan cache store'una milisaniye cinsinden "valid-after" zamanı yazılır; bu zamana kadar üretilen
+// token'lar AuthMiddleware tarafından reddedilir. Key, access token ömrü kadar tutulur;
+// sonrasında eski token'ların süresi zaten dolmuştur.
+func RevokeUserTokens(ctx context.Context, userID int64) error {
+	ttl := time.Duration(jwtConfig.Expiration) * time.Hour
+	return cache.Set(ctx, validAfterKey(userID), time.Now().UnixMilli(), ttl)
+}
+
+// CheckRevoked token kullanıcı için geçersiz kılınmışsa ErrTokenRevoked döner
+func CheckRevoked(ctx context.Context, claims *Claims) error {
+	var validAfter int64
+	if err := cache.Get(ctx, validAfterKey(claims.UserID), &validAfter); err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("token revocation check: %w", err)
+	}
+
+	issuedAt := claims.IssuedAtMs
+	if issuedAt == 0 && claims.IssuedAt != nil {
+		// iat_ms taşımayan eski token'larda saniyeye yuvarlanmış iat kullanılır
+		issuedAt = claims.IssuedAt.UnixMilli()
+	}
+	if issuedAt == 0 || issuedAt <= validAfter {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+func validAfterKey(userID int64) string {
+	return fmt.Sprintf("%s%d", validAfterKeyPrefix, userID)
+}
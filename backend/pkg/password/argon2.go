@@ -0,0 +1,102 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+	// Aktarılan hash'lerdeki parametreler girişte sunucuyu kilitlemesin
+	argon2MaxMemory      = 1024 * 1024 // KiB (1 GiB)
+	argon2MaxIterations  = 10
+	argon2MaxParallelism = 16
+	argon2MaxKeyLength   = 128
+)
+
+// Argon2idHasher $argon2id$v=19$m=<KiB>,t=<iterasyon>,p=<paralellik>$<salt>$<hash> üretir
+type Argon2idHasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+func NewArgon2idHasher(memory, iterations uint32, parallelism uint8) *Argon2idHasher {
+	return &Argon2idHasher{Memory: memory, Iterations: iterations, Parallelism: parallelism}
+}
+
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt, key   []byte
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Iterations, h.Parallelism, encodeB64(salt), encodeB64(key)), nil
+}
+
+func (h *Argon2idHasher) Verify(password, encoded string) (bool, error) {
+	p, err := parseArgon2(encoded)
+	if err != nil {
+		return false, err
+	}
+	key := argon2.IDKey([]byte(password), p.salt, p.iterations, p.memory, p.parallelism, uint32(len(p.key)))
+	return subtle.ConstantTimeCompare(key, p.key) == 1, nil
+}
+
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	p, err := parseArgon2(encoded)
+	if err != nil {
+		return true
+	}
+	return p.memory != h.Memory || p.iterations != h.Iterations || p.parallelism != h.Parallelism ||
+		len(p.salt) != argon2SaltLength || len(p.key) != argon2KeyLength
+}
+
+func (h *Argon2idHasher) Validate(encoded string) error {
+	_, err := parseArgon2(encoded)
+	return err
+}
+
+func parseArgon2(encoded string) (*argon2Params, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("%w: argon2 sürümü desteklenmiyor", ErrInvalidHash)
+	}
+
+	p := &argon2Params{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return nil, fmt.Errorf("%w: argon2 parametreleri okunamadı", ErrInvalidHash)
+	}
+	if p.memory == 0 || p.memory > argon2MaxMemory ||
+		p.iterations == 0 || p.iterations > argon2MaxIterations ||
+		p.parallelism == 0 || p.parallelism > argon2MaxParallelism {
+		return nil, fmt.Errorf("%w: argon2 parametreleri geçersiz", ErrInvalidHash)
+	}
+
+	var err error
+	if p.salt, err = decodeB64(parts[4]); err != nil {
+		return nil, ErrInvalidHash
+	}
+	if p.key, err = decodeB64(parts[5]); err != nil || len(p.key) == 0 || len(p.key) > argon2MaxKeyLength {
+		return nil, ErrInvalidHash
+	}
+	return p, nil
+}
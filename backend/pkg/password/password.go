@@ -0,0 +1,200 @@
+package password
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/config"
+	"strings"
+)
+
+// Desteklenen hash algoritmaları (PASSWORD_HASH_ALGORITHM)
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmPBKDF2   = "pbkdf2-sha256"
+)
+
+// Init çağrılmazsa kullanılan varsayılan parametreler
+const (
+	DefaultArgon2Memory      = 64 * 1024 // KiB
+	DefaultArgon2Iterations  = 3
+	DefaultArgon2Parallelism = 2
+	DefaultBcryptCost        = 10
+	DefaultPBKDF2Iterations  = 600000
+)
+
+var (
+	ErrUnknownAlgorithm = errors.New("desteklenmeyen hash algoritması")
+	ErrInvalidHash      = errors.New("hash formatı geçersiz")
+)
+
+// Hasher tek bir algoritma için PHC formatında ($<id>$<parametreler>$<salt>$<hash>)
+// hash üretir ve doğrular. Doğrulama hash içindeki parametrelerle yapılır;
+// NeedsRehash parametrelerin güncel ayarlardan farklı olup olmadığını söyler.
+type Hasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (bool, error)
+	NeedsRehash(encoded string) bool
+	// Validate hash'i hesaplamadan sadece formatını ve parametrelerini kontrol eder
+	Validate(encoded string) error
+}
+
+type registry struct {
+	current   Hasher
+	currentID string
+	byID      map[string]Hasher // PHC algoritma kimliği -> hasher
+}
+
+var (
+	hashers = newRegistry(DefaultConfig())
+	policy  = newPolicy(DefaultConfig())
+)
+
+// DefaultConfig Init çağrılmadığında kullanılan ayarları döner
+func DefaultConfig() config.PasswordConfig {
+	return config.PasswordConfig{
+		Algorithm:          AlgorithmArgon2id,
+		Argon2Memory:       DefaultArgon2Memory,
+		Argon2Iterations:   DefaultArgon2Iterations,
+		Argon2Parallelism:  DefaultArgon2Parallelism,
+		BcryptCost:         DefaultBcryptCost,
+		PBKDF2Iterations:   DefaultPBKDF2Iterations,
+		MinLength:          8,
+		MaxLength:          128,
+		RequireUpper:       true,
+		RequireLower:       true,
+		RequireDigit:       true,
+		ForbidPersonalInfo: true,
+		HistorySize:        5,
+	}
+}
+
+// Init yeni şifrelerin hangi algoritma ve parametrelerle hash'leneceğini ve
+// uyması gereken politikayı ayarlar. Diğer algoritmalarla üretilmiş hash'ler
+// doğrulanmaya devam eder.
+func Init(cfg *config.PasswordConfig) error {
+	r := newRegistry(*cfg)
+	if r.current == nil {
+		return fmt.Errorf("%w: %q", ErrUnknownAlgorithm, cfg.Algorithm)
+	}
+	// Sınırların dışındaki ayarlarla üretilen hash'ler daha sonra doğrulanamaz
+	if err := validateParams(*cfg); err != nil {
+		return err
+	}
+	hashers = r
+	policy = newPolicy(*cfg)
+	return nil
+}
+
+func newRegistry(cfg config.PasswordConfig) *registry {
+	argon := NewArgon2idHasher(uint32(cfg.Argon2Memory), uint32(cfg.Argon2Iterations), uint8(cfg.Argon2Parallelism))
+	bcryptHasher := NewBcryptHasher(cfg.BcryptCost)
+	pbkdf2Hasher := NewPBKDF2Hasher(cfg.PBKDF2Iterations)
+
+	r := &registry{byID: map[string]Hasher{
+		"argon2id":      argon,
+		"2a":            bcryptHasher,
+		"2b":            bcryptHasher,
+		"2y":            bcryptHasher,
+		"pbkdf2-sha1":   pbkdf2Hasher,
+		"pbkdf2-sha256": pbkdf2Hasher,
+		"pbkdf2-sha512": pbkdf2Hasher,
+	}}
+
+	switch strings.ToLower(cfg.Algorithm) {
+	case AlgorithmArgon2id:
+		r.current, r.currentID = argon, "argon2id"
+	case AlgorithmBcrypt:
+		r.current, r.currentID = bcryptHasher, "2a"
+	case AlgorithmPBKDF2:
+		r.current, r.currentID = pbkdf2Hasher, "pbkdf2-sha256"
+	}
+	return r
+}
+
+func validateParams(cfg config.PasswordConfig) error {
+	switch {
+	case cfg.Argon2Memory <= 0 || cfg.Argon2Memory > argon2MaxMemory:
+		return fmt.Errorf("argon2 bellek ayarı 1-%d KiB arasında olmalı", argon2MaxMemory)
+	case cfg.Argon2Iterations <= 0 || cfg.Argon2Iterations > argon2MaxIterations:
+		return fmt.Errorf("argon2 iterasyon sayısı 1-%d arasında olmalı", argon2MaxIterations)
+	case cfg.Argon2Parallelism <= 0 || cfg.Argon2Parallelism > argon2MaxParallelism:
+		return fmt.Errorf("argon2 paralellik ayarı 1-%d arasında olmalı", argon2MaxParallelism)
+	case cfg.PBKDF2Iterations <= 0 || cfg.PBKDF2Iterations > pbkdf2MaxIterations:
+		return fmt.Errorf("pbkdf2 iterasyon sayısı 1-%d arasında olmalı", pbkdf2MaxIterations)
+	}
+	return nil
+}
+
+// Hash şifreyi güncel algoritma ve parametrelerle hash'ler
+func Hash(password string) (string, error) {
+	return hashers.current.Hash(password)
+}
+
+// Verify şifreyi hash'in kendi algoritması ve parametreleriyle doğrular
+func Verify(password, encoded string) (bool, error) {
+	h, err := hasherFor(encoded)
+	if err != nil {
+		return false, err
+	}
+	return h.Verify(password, encoded)
+}
+
+// NeedsRehash hash güncel algoritma veya parametrelerle üretilmemişse true döner
+func NeedsRehash(encoded string) bool {
+	id, err := identify(encoded)
+	if err != nil {
+		return true
+	}
+	// bcrypt'in sürüm kimlikleri (2a/2b/2y) aynı algoritmadır
+	if hashers.byID[id] != hashers.current {
+		return true
+	}
+	return hashers.current.NeedsRehash(encoded)
+}
+
+// Validate başka bir sistemden aktarılan hash'in desteklenen bir formatta olup
+// olmadığını kontrol eder
+func Validate(encoded string) error {
+	h, err := hasherFor(encoded)
+	if err != nil {
+		return err
+	}
+	return h.Validate(encoded)
+}
+
+func hasherFor(encoded string) (Hasher, error) {
+	id, err := identify(encoded)
+	if err != nil {
+		return nil, err
+	}
+	h, ok := hashers.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, id)
+	}
+	return h, nil
+}
+
+// identify $<id>$... formatındaki hash'in algoritma kimliğini döner
+func identify(encoded string) (string, error) {
+	if !strings.HasPrefix(encoded, "$") {
+		return "", ErrInvalidHash
+	}
+	id, _, ok := strings.Cut(encoded[1:], "$")
+	if !ok || id == "" {
+		return "", ErrInvalidHash
+	}
+	return id, nil
+}
+
+// PHC formatı padding'siz standart base64 kullanır; passlib gibi sistemlerin
+// '+' yerine '.' kullanan varyantı da kabul edilir
+func encodeB64(b []byte) string {
+	return base64.RawStdEncoding.EncodeToString(b)
+}
+
+func decodeB64(s string) ([]byte, error) {
+	s = strings.ReplaceAll(strings.TrimRight(s, "="), ".", "+")
+	return base64.RawStdEncoding.DecodeString(s)
+}
@@ -0,0 +1,134 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	pbkdf2SaltLength = 16
+	// Aktarılan hash'lerdeki parametreler girişte sunucuyu dakikalarca meşgul etmesin
+	pbkdf2MaxIterations = 10_000_000
+	pbkdf2MaxKeyLength  = 128
+)
+
+var pbkdf2Digests = map[string]struct {
+	new    func() hash.Hash
+	keyLen int
+}{
+	"pbkdf2-sha1":   {sha1.New, sha1.Size},
+	"pbkdf2-sha256": {sha256.New, sha256.Size},
+	"pbkdf2-sha512": {sha512.New, sha512.Size},
+}
+
+// PBKDF2Hasher $pbkdf2-sha256$i=<iterasyon>,l=<uzunluk>$<salt>$<hash> üretir.
+// Aktarılan hash'ler için sha1/sha512 ve passlib formatı ($pbkdf2-sha256$<iterasyon>$...)
+// de doğrulanabilir.
+type PBKDF2Hasher struct {
+	Iterations int
+}
+
+func NewPBKDF2Hasher(iterations int) *PBKDF2Hasher {
+	return &PBKDF2Hasher{Iterations: iterations}
+}
+
+type pbkdf2Params struct {
+	id         string
+	iterations int
+	salt, key  []byte
+}
+
+func (h *PBKDF2Hasher) Hash(password string) (string, error) {
+	salt := make([]byte, pbkdf2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := pbkdf2.Key([]byte(password), salt, h.Iterations, sha256.Size, sha256.New)
+	return fmt.Sprintf("$pbkdf2-sha256$i=%d,l=%d$%s$%s", h.Iterations, sha256.Size, encodeB64(salt), encodeB64(key)), nil
+}
+
+func (h *PBKDF2Hasher) Verify(password, encoded string) (bool, error) {
+	p, err := parsePBKDF2(encoded)
+	if err != nil {
+		return false, err
+	}
+	key := pbkdf2.Key([]byte(password), p.salt, p.iterations, len(p.key), pbkdf2Digests[p.id].new)
+	return subtle.ConstantTimeCompare(key, p.key) == 1, nil
+}
+
+func (h *PBKDF2Hasher) NeedsRehash(encoded string) bool {
+	p, err := parsePBKDF2(encoded)
+	if err != nil {
+		return true
+	}
+	return p.id != "pbkdf2-sha256" || p.iterations != h.Iterations ||
+		len(p.salt) != pbkdf2SaltLength || len(p.key) != sha256.Size
+}
+
+func (h *PBKDF2Hasher) Validate(encoded string) error {
+	_, err := parsePBKDF2(encoded)
+	return err
+}
+
+func parsePBKDF2(encoded string) (*pbkdf2Params, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 {
+		return nil, ErrInvalidHash
+	}
+	digest, ok := pbkdf2Digests[parts[1]]
+	if !ok {
+		return nil, ErrInvalidHash
+	}
+
+	p := &pbkdf2Params{id: parts[1]}
+	keyLen := 0
+	if strings.Contains(parts[2], "=") {
+		for _, param := range strings.Split(parts[2], ",") {
+			name, value, _ := strings.Cut(param, "=")
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: pbkdf2 parametreleri okunamadı", ErrInvalidHash)
+			}
+			switch name {
+			case "i":
+				p.iterations = n
+			case "l":
+				keyLen = n
+			}
+		}
+	} else {
+		// passlib: sadece iterasyon sayısı
+		n, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("%w: pbkdf2 parametreleri okunamadı", ErrInvalidHash)
+		}
+		p.iterations = n
+	}
+	if p.iterations <= 0 || p.iterations > pbkdf2MaxIterations {
+		return nil, fmt.Errorf("%w: pbkdf2 iterasyon sayısı geçersiz", ErrInvalidHash)
+	}
+
+	var err error
+	if p.salt, err = decodeB64(parts[3]); err != nil {
+		return nil, ErrInvalidHash
+	}
+	if p.key, err = decodeB64(parts[4]); err != nil || len(p.key) == 0 || len(p.key) > pbkdf2MaxKeyLength {
+		return nil, ErrInvalidHash
+	}
+	if keyLen == 0 {
+		keyLen = digest.keyLen
+	}
+	if len(p.key) != keyLen {
+		return nil, fmt.Errorf("%w: hash uzunluğu parametreyle uyuşmuyor", ErrInvalidHash)
+	}
+	return p, nil
+}
@@ -0,0 +1,188 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/config"
+	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Politika kuralları; ihlaller istemciye bu kodlarla döner
+const (
+	RuleMinLength    = "min_length"
+	RuleMaxLength    = "max_length"
+	RuleUppercase    = "uppercase"
+	RuleLowercase    = "lowercase"
+	RuleDigit        = "digit"
+	RuleSymbol       = "symbol"
+	RulePersonalInfo = "personal_info"
+	RuleBreached     = "breached"
+	RuleHistory      = "history"
+)
+
+// Kişisel bilgi kontrolünde bu uzunluktan kısa parçalar (ör. "Al") yok sayılır
+const personalInfoMinLength = 3
+
+// Violation ihlal edilen tek bir politika kuralıdır
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Policy yeni belirlenen şifrelerin uyması gereken kurallardır
+type Policy struct {
+	MinLength          int
+	MaxLength          int
+	RequireUpper       bool
+	RequireLower       bool
+	RequireDigit       bool
+	RequireSymbol      bool
+	ForbidPersonalInfo bool
+	HistorySize        int
+	MaxAge             time.Duration
+	BreachedDir        string
+}
+
+func newPolicy(cfg config.PasswordConfig) Policy {
+	return Policy{
+		MinLength:          cfg.MinLength,
+		MaxLength:          cfg.MaxLength,
+		RequireUpper:       cfg.RequireUpper,
+		RequireLower:       cfg.RequireLower,
+		RequireDigit:       cfg.RequireDigit,
+		RequireSymbol:      cfg.RequireSymbol,
+		ForbidPersonalInfo: cfg.ForbidPersonalInfo,
+		HistorySize:        cfg.HistorySize,
+		MaxAge:             time.Duration(cfg.MaxAgeDays) * 24 * time.Hour,
+		BreachedDir:        cfg.BreachedDir,
+	}
+}
+
+// CurrentPolicy Init ile ayarlanan politikayı döner
+func CurrentPolicy() Policy {
+	return policy
+}
+
+// CheckPolicy şifreyi güncel politikaya göre kontrol eder. personal kullanıcının
+// e-posta, ad, soyad gibi şifrede geçmemesi gereken bilgileridir.
+// Şifre geçmişi kullanıcı kaydına bağlı olduğu için model.User üzerinde kontrol edilir.
+func CheckPolicy(plain string, personal ...string) []Violation {
+	return policy.Check(plain, personal...)
+}
+
+// Check şifrenin ihlal ettiği tüm kuralları döner; şifre uygunsa boş döner
+func (p Policy) Check(plain string, personal ...string) []Violation {
+	var violations []Violation
+
+	length := utf8.RuneCountInString(plain)
+	if p.MinLength > 0 && length < p.MinLength {
+		violations = append(violations, Violation{RuleMinLength, fmt.Sprintf("Şifre en az %d karakter olmalı", p.MinLength)})
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, Violation{RuleMaxLength, fmt.Sprintf("Şifre en fazla %d karakter olabilir", p.MaxLength)})
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range plain {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		violations = append(violations, Violation{RuleUppercase, "Şifre en az bir büyük harf içermeli"})
+	}
+	if p.RequireLower && !lower {
+		violations = append(violations, Violation{RuleLowercase, "Şifre en az bir küçük harf içermeli"})
+	}
+	if p.RequireDigit && !digit {
+		violations = append(violations, Violation{RuleDigit, "Şifre en az bir rakam içermeli"})
+	}
+	if p.RequireSymbol && !symbol {
+		violations = append(violations, Violation{RuleSymbol, "Şifre en az bir özel karakter içermeli"})
+	}
+
+	if p.ForbidPersonalInfo && containsPersonalInfo(plain, personal) {
+		violations = append(violations, Violation{RulePersonalInfo, "Şifre e-posta adresinizi, adınızı veya kullanıcı adınızı içeremez"})
+	}
+
+	if p.BreachedDir != "" && breached(p.BreachedDir, plain) {
+		violations = append(violations, Violation{RuleBreached, "Bu şifre bilinen veri sızıntılarında yer alıyor, başka bir şifre seçin"})
+	}
+
+	return violations
+}
+
+// HistoryViolation şifrenin son kullanılan şifrelerden biri olduğunu bildirir
+func HistoryViolation() Violation {
+	return Violation{RuleHistory, fmt.Sprintf("Son %d şifrenizden biri tekrar kullanılamaz", policy.HistorySize)}
+}
+
+// PolicyError ihlalleri kural bazında detaylarıyla birlikte doğrulama hatasına çevirir
+func PolicyError(violations []Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return errorx.WithDetails(errorx.ErrValidation, "Şifre, şifre politikasına uymuyor", violations)
+}
+
+// E-postanın yerel kısmı ve ad/soyad/kullanıcı adı parçaları büyük/küçük harf duyarsız aranır
+func containsPersonalInfo(plain string, personal []string) bool {
+	lowered := strings.ToLower(plain)
+	for _, info := range personal {
+		info = strings.ToLower(info)
+		if local, _, ok := strings.Cut(info, "@"); ok {
+			info = local
+		}
+		parts := strings.FieldsFunc(info, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, part := range parts {
+			if utf8.RuneCountInString(part) >= personalInfoMinLength && strings.Contains(lowered, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// breached şifrenin SHA-1 hash'ini k-anonimlik formatındaki yerel veri setinde arar:
+// dir altında hash'in ilk 5 hanesi adlı (isteğe bağlı .txt uzantılı) dosyada
+// "SUFFIX:COUNT" satırları bulunur. Veri seti okunamazsa şifre reddedilmez.
+func breached(dir, plain string) bool {
+	sum := sha1.Sum([]byte(plain))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	f, err := os.Open(filepath.Join(dir, prefix))
+	if err != nil {
+		if f, err = os.Open(filepath.Join(dir, prefix+".txt")); err != nil {
+			return false
+		}
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, count, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		// Sayısı 0 olan satırlar yanıt boyutunu gizlemek için eklenen dolgulardır
+		if strings.EqualFold(line, suffix) {
+			return count != "0"
+		}
+	}
+	return false
+}
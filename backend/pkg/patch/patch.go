@@ -0,0 +1,333 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Desteklenen patch içerik tipleri
+const (
+	ContentTypeMergePatch = "application/merge-patch+json" // RFC 7396
+	ContentTypeJSONPatch  = "application/json-patch+json"  // RFC 6902
+)
+
+var (
+	ErrInvalidPatch  = errors.New("geçersiz patch dokümanı")
+	ErrPathNotFound  = errors.New("patch yolu bulunamadı")
+	ErrTestFailed    = errors.New("patch test işlemi başarısız")
+	ErrInvalidTarget = errors.New("patch hedefi obje veya dizi değil")
+)
+
+// Apply içerik tipine göre merge patch veya JSON patch uygular.
+// Tanınmayan içerik tipleri (ör. application/json) merge patch olarak yorumlanır.
+func Apply(contentType string, doc, patch []byte) ([]byte, error) {
+	if strings.HasPrefix(contentType, ContentTypeJSONPatch) {
+		return ApplyJSONPatch(doc, patch)
+	}
+	return MergePatch(doc, patch)
+}
+
+// MergePatch RFC 7396'ya göre patch'i dokümana uygular
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target interface{}
+	if len(bytes.TrimSpace(doc)) > 0 {
+		if err := json.Unmarshal(doc, &target); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+	}
+
+	var p interface{}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	return json.Marshal(mergeValue(target, p))
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		// Obje olmayan patch hedefin tamamını değiştirir
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = mergeValue(targetObj[key], value)
+	}
+	return targetObj
+}
+
+// Operation RFC 6902 JSON patch işlemidir
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ApplyJSONPatch RFC 6902'ye göre işlemleri sırayla uygular.
+// İşlemlerden biri başarısız olursa doküman değiştirilmez.
+func ApplyJSONPatch(doc, patch []byte) ([]byte, error) {
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	var root interface{}
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	var err error
+	for i, op := range ops {
+		if root, err = applyOperation(root, op); err != nil {
+			return nil, fmt.Errorf("işlem %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	return json.Marshal(root)
+}
+
+func applyOperation(root interface{}, op Operation) (interface{}, error) {
+	switch op.Op {
+	case "add":
+		value, err := decodeValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, op.Path, value)
+	case "remove":
+		root, _, err := remove(root, op.Path)
+		return root, err
+	case "replace":
+		value, err := decodeValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		if root, _, err = remove(root, op.Path); err != nil {
+			return nil, err
+		}
+		return add(root, op.Path, value)
+	case "move":
+		root, value, err := remove(root, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, op.Path, value)
+	case "copy":
+		value, err := get(root, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, op.Path, deepCopy(value))
+	case "test":
+		expected, err := decodeValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		actual, err := get(root, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(actual, expected) {
+			return nil, ErrTestFailed
+		}
+		return root, nil
+	}
+	return nil, fmt.Errorf("%w: bilinmeyen işlem %q", ErrInvalidPatch, op.Op)
+}
+
+// Pointer RFC 6901 JSON pointer'ını parçalarına ayırır
+func Pointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("%w: geçersiz yol %q", ErrInvalidPatch, path)
+	}
+	parts := strings.Split(path[1:], "/")
+	for i, p := range parts {
+		parts[i] = strings.ReplaceAll(strings.ReplaceAll(p, "~1", "/"), "~0", "~")
+	}
+	return parts, nil
+}
+
+func get(root interface{}, path string) (interface{}, error) {
+	parts, err := Pointer(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := root
+	for _, part := range parts {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[part]
+			if !ok {
+				return nil, ErrPathNotFound
+			}
+			current = value
+		case []interface{}:
+			idx, err := arrayIndex(part, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			current = node[idx]
+		default:
+			return nil, ErrPathNotFound
+		}
+	}
+	return current, nil
+}
+
+func add(root interface{}, path string, value interface{}) (interface{}, error) {
+	parts, err := Pointer(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(root, joinPointer(parts[:len(parts)-1]))
+	if err != nil {
+		return nil, err
+	}
+	last := parts[len(parts)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+		return root, nil
+	case []interface{}:
+		idx := len(node)
+		if last != "-" {
+			if idx, err = arrayIndex(last, len(node)); err != nil {
+				return nil, err
+			}
+		}
+		node = append(node, nil)
+		copy(node[idx+1:], node[idx:])
+		node[idx] = value
+		return replaceParent(root, parts[:len(parts)-1], node)
+	}
+	return nil, ErrInvalidTarget
+}
+
+func remove(root interface{}, path string) (interface{}, interface{}, error) {
+	parts, err := Pointer(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(parts) == 0 {
+		return nil, root, nil
+	}
+
+	parent, err := get(root, joinPointer(parts[:len(parts)-1]))
+	if err != nil {
+		return nil, nil, err
+	}
+	last := parts[len(parts)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		value, ok := node[last]
+		if !ok {
+			return nil, nil, ErrPathNotFound
+		}
+		delete(node, last)
+		return root, value, nil
+	case []interface{}:
+		idx, err := arrayIndex(last, len(node)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := node[idx]
+		node = append(node[:idx:idx], node[idx+1:]...)
+		root, err = replaceParent(root, parts[:len(parts)-1], node)
+		return root, value, err
+	}
+	return nil, nil, ErrInvalidTarget
+}
+
+// Dizi uzunluğu değiştiğinde yeni slice'ı üst düğüme yazar
+func replaceParent(root interface{}, parts []string, value interface{}) (interface{}, error) {
+	if len(parts) == 0 {
+		return value, nil
+	}
+	grand, err := get(root, joinPointer(parts[:len(parts)-1]))
+	if err != nil {
+		return nil, err
+	}
+	last := parts[len(parts)-1]
+
+	switch node := grand.(type) {
+	case map[string]interface{}:
+		node[last] = value
+	case []interface{}:
+		idx, err := arrayIndex(last, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		node[idx] = value
+	default:
+		return nil, ErrInvalidTarget
+	}
+	return root, nil
+}
+
+func arrayIndex(part string, max int) (int, error) {
+	idx, err := strconv.Atoi(part)
+	if err != nil || idx < 0 || idx > max || (len(part) > 1 && part[0] == '0') {
+		return 0, ErrPathNotFound
+	}
+	return idx, nil
+}
+
+func joinPointer(parts []string) string {
+	if len(parts) == 0 {
+		return ""
+	}
+	escaped := make([]string, len(parts))
+	for i, p := range parts {
+		escaped[i] = strings.ReplaceAll(strings.ReplaceAll(p, "~", "~0"), "/", "~1")
+	}
+	return "/" + strings.Join(escaped, "/")
+}
+
+func decodeValue(raw json.RawMessage) (interface{}, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("%w: value alanı gerekli", ErrInvalidPatch)
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return v, nil
+}
+
+func deepCopy(v interface{}) interface{} {
+	raw, _ := json.Marshal(v)
+	var out interface{}
+	_ = json.Unmarshal(raw, &out)
+	return out
+}
+
+func jsonEqual(a, b interface{}) bool {
+	ra, _ := json.Marshal(a)
+	rb, _ := json.Marshal(b)
+	return bytes.Equal(ra, rb)
+}
@@ -1,6 +1,10 @@
 package response
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"errors"
+	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"github.com/gofiber/fiber/v2"
+)
 
 const (
 	StatusOK = fiber.StatusOK
@@ -11,6 +15,7 @@ type Response struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
 	Message interface{} `json:"message,omitempty"`
+	Errors  interface{} `json:"errors,omitempty"`
 }
 
 // Başarılı yanıt oluşturmak için yardımcı fonksiyonlar
@@ -27,9 +32,44 @@ func Success(c *fiber.Ctx, data interface{}, message ...string) error {
 	})
 }
 
+// İşlem kabul edildi, arka planda tamamlanacak (202)
+func Accepted(c *fiber.Ctx, data interface{}, message ...string) error {
+	var msg interface{}
+	if len(message) > 0 {
+		msg = message[0]
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(Response{
+		Success: true,
+		Data:    data,
+		Message: msg,
+	})
+}
+
 // Başarılı yanıt - veri olmadan
 func SuccessNoData(c *fiber.Ctx) error {
 	return c.Status(StatusOK).JSON(Response{
 		Success: true,
 	})
 }
+
+// ErrorHandler errorx.AppError ve fiber.Error hatalarını kendi HTTP kodlarıyla döner
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	code := fiber.StatusInternalServerError
+
+	var details interface{}
+	var appErr *errorx.AppError
+	var fiberErr *fiber.Error
+	if errors.As(err, &appErr) {
+		code = appErr.Code
+		details = appErr.Details
+	} else if errors.As(err, &fiberErr) {
+		code = fiberErr.Code
+	}
+
+	return c.Status(code).JSON(Response{
+		Success: false,
+		Message: err.Error(),
+		Errors:  details,
+	})
+}
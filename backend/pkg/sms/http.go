@@ -0,0 +1,68 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPSender SMS'i genel bir HTTP API'ye JSON olarak POST eder:
+//
+//	{"from": "...", "to": "+905551112233", "message": "..."}
+//
+// Token verilmişse "Authorization: Bearer <token>" başlığı eklenir. 2xx dışındaki
+// yanıtlar hata sayılır. Farklı bir gövde bekleyen sağlayıcılar için araya küçük
+// bir proxy/adaptör konulabilir.
+type HTTPSender struct {
+	url    string
+	token  string
+	from   string
+	client *http.Client
+}
+
+type httpMessage struct {
+	From    string `json:"from,omitempty"`
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
+
+func NewHTTPSender(url, token, from string, timeout time.Duration) *HTTPSender {
+	return &HTTPSender{
+		url:    url,
+		token:  token,
+		from:   from,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *HTTPSender) Send(ctx context.Context, to, message string) error {
+	body, err := json.Marshal(httpMessage{From: s.from, To: to, Message: message})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sms: istek gönderilemedi: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sms: sağlayıcı %d döndü: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
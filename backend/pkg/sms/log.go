@@ -0,0 +1,41 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/pkg/logger"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogSender geliştirme ortamı içindir: mesajları göndermek yerine dosyaya veya
+// (path boşsa) uygulama loguna yazar
+type LogSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewLogSender(path string) *LogSender {
+	return &LogSender{path: path}
+}
+
+func (s *LogSender) Send(_ context.Context, to, message string) error {
+	if s.path == "" {
+		logger.Info("SMS -> %s: %s", to, message)
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "%s\t%s\t%s\n", time.Now().Format(time.RFC3339), to, message)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
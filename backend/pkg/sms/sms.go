@@ -0,0 +1,33 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/config"
+	"time"
+)
+
+const (
+	ProviderLog  = "log"
+	ProviderHTTP = "http"
+)
+
+// SMSSender SMS gönderim sağlayıcısıdır. to E.164 formatında telefon numarasıdır.
+type SMSSender interface {
+	Send(ctx context.Context, to, message string) error
+}
+
+// New yapılandırmadaki sağlayıcıya göre SMSSender oluşturur
+func New(cfg *config.SMSConfig) (SMSSender, error) {
+	switch cfg.Provider {
+	case ProviderLog, "":
+		return NewLogSender(cfg.LogFile), nil
+	case ProviderHTTP:
+		if cfg.HTTPURL == "" {
+			return nil, fmt.Errorf("sms: http provider için SMS_HTTP_URL gerekli")
+		}
+		return NewHTTPSender(cfg.HTTPURL, cfg.HTTPToken, cfg.From, time.Duration(cfg.HTTPTimeout)*time.Second), nil
+	default:
+		return nil, fmt.Errorf("sms: bilinmeyen provider: %s", cfg.Provider)
+	}
+}
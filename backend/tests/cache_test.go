@@ -0,0 +1,430 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"github.com/Furkanturan8/goftr-template/config"
+	"github.com/Furkanturan8/goftr-template/pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Set Get Delete", func(t *testing.T) {
+		c := cache.NewMemoryCache(10)
+		assert.NoError(t, c.Set(ctx, "user:1", map[string]string{"email": "a@b.com"}, 0))
+
+		var got map[string]string
+		assert.NoError(t, c.Get(ctx, "user:1", &got))
+		assert.Equal(t, "a@b.com", got["email"])
+
+		assert.NoError(t, c.Delete(ctx, "user:1"))
+		assert.ErrorIs(t, c.Get(ctx, "user:1", &got), cache.ErrNotFound)
+	})
+
+	t.Run("TTL", func(t *testing.T) {
+		c := cache.NewMemoryCache(10)
+		assert.NoError(t, c.Set(ctx, "otp", 1, 20*time.Millisecond))
+		exists, _ := c.Exists(ctx, "otp")
+		assert.True(t, exists)
+
+		time.Sleep(30 * time.Millisecond)
+		exists, _ = c.Exists(ctx, "otp")
+		assert.False(t, exists)
+
+		assert.NoError(t, c.Set(ctx, "otp", 1, 20*time.Millisecond))
+		assert.NoError(t, c.Expire(ctx, "otp", time.Hour))
+		time.Sleep(30 * time.Millisecond)
+		exists, _ = c.Exists(ctx, "otp")
+		assert.True(t, exists, "Expire süreyi uzatır")
+	})
+
+	t.Run("LRU Eviction", func(t *testing.T) {
+		c := cache.NewMemoryCache(2)
+		_ = c.Set(ctx, "a", 1, 0)
+		_ = c.Set(ctx, "b", 2, 0)
+
+		var v int
+		_ = c.Get(ctx, "a", &v) // a en son kullanılan olur
+		_ = c.Set(ctx, "c", 3, 0)
+
+		assert.Equal(t, 2, c.Len())
+		assert.NoError(t, c.Get(ctx, "a", &v))
+		assert.ErrorIs(t, c.Get(ctx, "b", &v), cache.ErrNotFound)
+	})
+
+	t.Run("DeleteMany", func(t *testing.T) {
+		c := cache.NewMemoryCache(10)
+		_ = c.Set(ctx, "users:list", 1, 0)
+		_ = c.Set(ctx, "users:list:{\"page\":2}", 1, 0)
+		_ = c.Set(ctx, "user:1", 1, 0)
+
+		assert.NoError(t, c.DeleteMany(ctx, "users:list*"))
+		assert.Equal(t, 1, c.Len())
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		c := cache.NewMemoryCache(10)
+		_ = c.SetWithTags(ctx, "user:42", 1, 0, "user:42")
+		_ = c.SetWithTags(ctx, "user:email:a@b.com", 42, 0, "user:42")
+		_ = c.SetWithTags(ctx, "users:list:1", 1, 0, "users:list")
+		_ = c.Set(ctx, "other", 1, 0)
+
+		assert.NoError(t, c.InvalidateTags(ctx, "user:42"))
+		assert.Equal(t, 2, c.Len())
+
+		// Redis'teki gibi Set tag'lere dokunmaz, yeniden yazılan key eski tag ile de silinir
+		_ = c.Set(ctx, "users:list:1", 2, 0)
+		assert.NoError(t, c.InvalidateTags(ctx, "users:list", "unknown"))
+		exists, _ := c.Exists(ctx, "users:list:1")
+		assert.False(t, exists)
+
+		// Farklı tag'lerle tekrar yazmak tag'leri biriktirir
+		_ = c.SetWithTags(ctx, "user:7", 1, 0, "user:7")
+		_ = c.SetWithTags(ctx, "user:7", 2, 0, "users:list")
+		assert.NoError(t, c.InvalidateTags(ctx, "user:7"))
+		exists, _ = c.Exists(ctx, "user:7")
+		assert.False(t, exists)
+	})
+}
+
+func TestNoopCacheAndFactory(t *testing.T) {
+	ctx := context.Background()
+
+	c := cache.NewNoopCache()
+	assert.NoError(t, c.Set(ctx, "k", 1, time.Minute))
+	var v int
+	assert.ErrorIs(t, c.Get(ctx, "k", &v), cache.ErrNotFound)
+
+	store, err := cache.New(&config.CacheConfig{Driver: cache.DriverMemory}, &config.RedisConfig{})
+	assert.NoError(t, err)
+	assert.IsType(t, &cache.MemoryCache{}, store)
+
+	_, err = cache.New(&config.CacheConfig{Driver: "memcached"}, &config.RedisConfig{})
+	assert.Error(t, err)
+
+	// Redis'e ulaşılamazsa sadece açıkça istenirse başka backend'e geçilir
+	unreachable := &config.RedisConfig{Addrs: []string{"127.0.0.1:1"}}
+	store, err = cache.New(&config.CacheConfig{Driver: cache.DriverRedis}, unreachable)
+	assert.NoError(t, err)
+	assert.IsType(t, &cache.RedisCache{}, store)
+	_ = store.Close()
+
+	store, err = cache.New(&config.CacheConfig{Driver: cache.DriverRedis, Fallback: cache.DriverMemory}, unreachable)
+	assert.NoError(t, err)
+	assert.IsType(t, &cache.MemoryCache{}, store)
+
+	_, err = cache.New(&config.CacheConfig{Driver: cache.DriverRedis, Fallback: "disk"}, unreachable)
+	assert.Error(t, err)
+}
+
+func TestGetOrLoad(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Concurrent Misses Load Once", func(t *testing.T) {
+		store := cache.NewMemoryCache(10)
+		var calls int32
+		loader := func(ctx context.Context) ([]string, error) {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(50 * time.Millisecond)
+			return []string{"a", "b"}, nil
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v, err := cache.GetOrLoad(ctx, store, "load:concurrent", time.Minute, loader)
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"a", "b"}, v)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+		// Sonraki okuma cache'ten gelir
+		_, _ = cache.GetOrLoad(ctx, store, "load:concurrent", time.Minute, loader)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Stale While Revalidate", func(t *testing.T) {
+		store := cache.NewMemoryCache(10)
+		var version int32
+		loader := func(ctx context.Context) (int32, error) {
+			return atomic.AddInt32(&version, 1), nil
+		}
+		opts := []cache.LoadOption{cache.WithStale(time.Second), cache.WithJitter(0)}
+
+		v, _ := cache.GetOrLoad(ctx, store, "load:stale", 20*time.Millisecond, loader, opts...)
+		assert.Equal(t, int32(1), v)
+
+		time.Sleep(30 * time.Millisecond)
+		v, _ = cache.GetOrLoad(ctx, store, "load:stale", 20*time.Millisecond, loader, opts...)
+		assert.Equal(t, int32(1), v, "bayat değer beklemeden döner")
+
+		assert.Eventually(t, func() bool {
+			v, _ := cache.GetOrLoad(ctx, store, "load:stale", time.Minute, loader, opts...)
+			return v == 2
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("Waits For Other Instance", func(t *testing.T) {
+		store := cache.NewMemoryCache(10)
+		// Başka bir instance kilidi almış ve yüklüyor
+		_, _, _ = store.AcquireLock(ctx, "load:load:remote", "other-instance", time.Second)
+		go func() {
+			time.Sleep(60 * time.Millisecond)
+			_ = store.Set(ctx, "load:remote", map[string]interface{}{"v": 7, "f": time.Now().Add(time.Minute).UnixMilli()}, time.Minute)
+		}()
+
+		v, err := cache.GetOrLoad(ctx, store, "load:remote", time.Minute, func(context.Context) (int, error) {
+			return 0, errors.New("loader çağrılmamalı")
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 7, v)
+	})
+
+	t.Run("Does Not Release Foreign Lock", func(t *testing.T) {
+		store := cache.NewMemoryCache(10)
+		// Diğer instance kilidi tutuyor ama zamanında yazamıyor; kendimiz yükleriz
+		_, _, _ = store.AcquireLock(ctx, "load:load:foreign", "other-instance", time.Minute)
+		v, err := cache.GetOrLoad(ctx, store, "load:foreign", time.Minute, func(ctx context.Context) (int, error) {
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline, "paylaşılan yüklemenin süresi sınırlı olmalı")
+			return 5, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 5, v)
+
+		released, _ := store.ReleaseLock(ctx, "load:load:foreign", "other-instance")
+		assert.True(t, released, "başkasının kilidi silinmemeli")
+	})
+
+	t.Run("Loader Error Is Not Cached", func(t *testing.T) {
+		store := cache.NewMemoryCache(10)
+		_, err := cache.GetOrLoad(ctx, store, "load:error", time.Minute, func(context.Context) (int, error) {
+			return 0, errors.New("db hatası")
+		})
+		assert.Error(t, err)
+
+		v, err := cache.GetOrLoad(ctx, store, "load:error", time.Minute, func(context.Context) (int, error) {
+			return 3, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, v)
+	})
+}
+
+// Kilidi başka bir instance'a kaptırmış gibi davranır
+type stolenLocker struct {
+	*cache.MemoryCache
+	stolen atomic.Bool
+}
+
+func (l *stolenLocker) RenewLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	if l.stolen.Load() {
+		return false, nil
+	}
+	return l.MemoryCache.RenewLock(ctx, key, owner, ttl)
+}
+
+func (l *stolenLocker) ReleaseLock(ctx context.Context, key, owner string) (bool, error) {
+	if l.stolen.Load() {
+		return false, nil
+	}
+	return l.MemoryCache.ReleaseLock(ctx, key, owner)
+}
+
+// Redis'e ulaşılamıyormuş gibi kilidi uzatamaz
+type unreachableLocker struct {
+	*cache.MemoryCache
+	down atomic.Bool
+}
+
+func (l *unreachableLocker) RenewLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	if l.down.Load() {
+		return false, errors.New("bağlantı yok")
+	}
+	return l.MemoryCache.RenewLock(ctx, key, owner, ttl)
+}
+
+func TestLock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("TryLock And Fencing Token", func(t *testing.T) {
+		store := cache.NewMemoryCache(10)
+		first, err := cache.TryLock(ctx, store, "job:a", time.Second)
+		assert.NoError(t, err)
+
+		_, err = cache.TryLock(ctx, store, "job:a", time.Second)
+		assert.ErrorIs(t, err, cache.ErrLockHeld)
+
+		assert.NoError(t, first.Release(ctx))
+		assert.NoError(t, first.Release(ctx), "tekrar çağrılabilir")
+		assert.Error(t, first.Context().Err())
+
+		second, err := cache.TryLock(ctx, store, "job:a", time.Second)
+		assert.NoError(t, err)
+		assert.Greater(t, second.Token(), first.Token())
+		assert.NoError(t, second.Release(ctx))
+	})
+
+	t.Run("Only Owner Releases", func(t *testing.T) {
+		store := cache.NewMemoryCache(10)
+		lease, _ := cache.TryLock(ctx, store, "job:b", time.Second)
+		released, err := store.ReleaseLock(ctx, "job:b", "başkası")
+		assert.NoError(t, err)
+		assert.False(t, released)
+		assert.NoError(t, lease.Release(ctx))
+	})
+
+	t.Run("Lease Renewal", func(t *testing.T) {
+		store := cache.NewMemoryCache(10)
+		lease, _ := cache.TryLock(ctx, store, "job:c", 60*time.Millisecond)
+		time.Sleep(200 * time.Millisecond)
+
+		_, err := cache.TryLock(ctx, store, "job:c", time.Second)
+		assert.ErrorIs(t, err, cache.ErrLockHeld, "süresi otomatik uzatılır")
+		assert.NoError(t, lease.Release(ctx))
+	})
+
+	t.Run("Lock Waits", func(t *testing.T) {
+		store := cache.NewMemoryCache(10)
+		lease, _ := cache.TryLock(ctx, store, "job:d", time.Second)
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_ = lease.Release(ctx)
+		}()
+
+		next, err := cache.Lock(ctx, store, "job:d", time.Second)
+		assert.NoError(t, err)
+
+		timeout, cancel := context.WithTimeout(ctx, 150*time.Millisecond)
+		defer cancel()
+		_, err = cache.Lock(timeout, store, "job:d", time.Second)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NoError(t, next.Release(ctx))
+	})
+
+	t.Run("Context Cancel Releases", func(t *testing.T) {
+		store := cache.NewMemoryCache(10)
+		parent, cancel := context.WithCancel(ctx)
+		_, err := cache.TryLock(parent, store, "job:e", time.Minute)
+		assert.NoError(t, err)
+		cancel()
+
+		assert.Eventually(t, func() bool {
+			lease, err := cache.TryLock(ctx, store, "job:e", time.Minute)
+			if err != nil {
+				return false
+			}
+			_ = lease.Release(ctx)
+			return true
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Lost Lease", func(t *testing.T) {
+		locker := &stolenLocker{MemoryCache: cache.NewMemoryCache(10)}
+		lease, _ := cache.TryLock(ctx, locker, "job:f", 60*time.Millisecond)
+		locker.stolen.Store(true)
+
+		select {
+		case <-lease.Context().Done():
+		case <-time.After(time.Second):
+			t.Fatal("kaybedilen kilidin context'i iptal edilmeli")
+		}
+		assert.ErrorIs(t, lease.Release(ctx), cache.ErrLockLost)
+	})
+
+	t.Run("Unrenewable Lease Stops Before Expiry", func(t *testing.T) {
+		locker := &unreachableLocker{MemoryCache: cache.NewMemoryCache(10)}
+		ttl := 600 * time.Millisecond
+		lease, _ := cache.TryLock(ctx, locker, "job:g", ttl)
+		time.Sleep(ttl / 2)
+		locker.down.Store(true)
+
+		select {
+		case <-lease.Context().Done():
+		case <-time.After(2 * time.Second):
+			t.Fatal("uzatılamayan kilidin context'i iptal edilmeli")
+		}
+		// Son başarılı uzatma ttl/3'te; kilit ttl/3+ttl'de düşer, iptal ondan pay bırakarak gelmeli
+		_, acquired, _ := locker.AcquireLock(ctx, "job:g", "başkası", ttl)
+		assert.False(t, acquired, "iptal kilit süresi dolmadan gelmeli")
+		_ = lease.Release(ctx)
+	})
+}
+
+func TestRedisOptions(t *testing.T) {
+	base := config.RedisConfig{Host: "localhost", Port: 6379, PoolSize: 20, MinIdleConns: 4, MaxRetries: 3, RetryInterval: 100}
+
+	t.Run("Standalone", func(t *testing.T) {
+		opts, err := cache.RedisOptions(&base)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"localhost:6379"}, opts.Addrs)
+		assert.Equal(t, 20, opts.PoolSize)
+		assert.Equal(t, 4, opts.MinIdleConns)
+		assert.Equal(t, 100*time.Millisecond, opts.MinRetryBackoff)
+		assert.Nil(t, opts.TLSConfig)
+
+		cfg := base
+		cfg.Addrs = []string{"a:6379", "b:6379"}
+		_, err = cache.RedisOptions(&cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("Sentinel", func(t *testing.T) {
+		cfg := base
+		cfg.Mode = cache.RedisModeSentinel
+		cfg.Addrs = []string{"s1:26379", "s2:26379"}
+		_, err := cache.RedisOptions(&cfg)
+		assert.Error(t, err, "master adı zorunlu")
+
+		cfg.MasterName = "mymaster"
+		cfg.SentinelPassword = "secret"
+		opts, err := cache.RedisOptions(&cfg)
+		assert.NoError(t, err)
+		assert.Equal(t, "mymaster", opts.MasterName)
+		assert.Equal(t, "secret", opts.SentinelPassword)
+	})
+
+	t.Run("Cluster", func(t *testing.T) {
+		cfg := base
+		cfg.Mode = cache.RedisModeCluster
+		cfg.DB = 1
+		_, err := cache.RedisOptions(&cfg)
+		assert.Error(t, err)
+
+		cfg.DB = 0
+		opts, err := cache.RedisOptions(&cfg)
+		assert.NoError(t, err)
+		assert.True(t, opts.IsClusterMode)
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		cfg := base
+		cfg.Username = "app"
+		cfg.TLS = config.RedisTLSConfig{Enabled: true, ServerName: "redis.internal"}
+		opts, err := cache.RedisOptions(&cfg)
+		assert.NoError(t, err)
+		assert.Equal(t, "app", opts.Username)
+		assert.Equal(t, "redis.internal", opts.TLSConfig.ServerName)
+
+		cfg.TLS.CAFile = "/olmayan/ca.pem"
+		_, err = cache.RedisOptions(&cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("Unknown Mode", func(t *testing.T) {
+		cfg := base
+		cfg.Mode = "replica"
+		_, err := cache.RedisOptions(&cfg)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,39 @@
+package tests
+
+import (
+	"github.com/Furkanturan8/goftr-template/pkg/etag"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestETag(t *testing.T) {
+	tag := etag.Format(3)
+	assert.Equal(t, `"3"`, tag)
+
+	t.Run("Parse", func(t *testing.T) {
+		version, ok := etag.Parse(`W/"3"`)
+		assert.True(t, ok)
+		assert.Equal(t, int64(3), version)
+
+		_, ok = etag.Parse("3")
+		assert.False(t, ok)
+	})
+
+	t.Run("Match", func(t *testing.T) {
+		assert.True(t, etag.Match(`"3"`, tag))
+		assert.True(t, etag.Match(`"1", "3"`, tag))
+		assert.True(t, etag.Match(`W/"3"`, tag))
+		assert.True(t, etag.Match("*", tag))
+		assert.False(t, etag.Match(`"2"`, tag))
+		assert.False(t, etag.Match("", tag))
+	})
+
+	t.Run("Match Strong", func(t *testing.T) {
+		assert.True(t, etag.MatchStrong(`"3"`, tag))
+		assert.True(t, etag.MatchStrong(`W/"1", "3"`, tag))
+		assert.True(t, etag.MatchStrong("*", tag))
+		assert.False(t, etag.MatchStrong(`W/"3"`, tag), "zayıf ETag If-Match'te eşleşmez")
+		assert.False(t, etag.MatchStrong(`"3"`, `W/"3"`))
+		assert.False(t, etag.MatchStrong("", tag))
+	})
+}
@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/Furkanturan8/goftr-template/pkg/export"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func writeExport(t *testing.T, format string) []byte {
+	var buf bytes.Buffer
+	w, err := export.NewWriter(format, &buf)
+	assert.NoError(t, err)
+	assert.NoError(t, w.WriteHeader([]string{"id", "email"}))
+	assert.NoError(t, w.WriteRow([]string{"1", "a@b.com"}))
+	assert.NoError(t, w.WriteRow([]string{"2", "=HYPERLINK(\"x\") & <tag>"}))
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestExportWriters(t *testing.T) {
+	t.Run("CSV", func(t *testing.T) {
+		out := writeExport(t, export.FormatCSV)
+		assert.Equal(t, "id,email\n1,a@b.com\n2,\"'=HYPERLINK(\"\"x\"\") & <tag>\"\n", string(out))
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		out := writeExport(t, export.FormatNDJSON)
+		assert.Equal(t, "{\"id\":\"1\",\"email\":\"a@b.com\"}\n{\"id\":\"2\",\"email\":\"=HYPERLINK(\\\"x\\\") \\u0026 \\u003ctag\\u003e\"}\n", string(out))
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		out := writeExport(t, export.FormatXLSX)
+		zr, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+		assert.NoError(t, err)
+
+		var sheet []byte
+		for _, f := range zr.File {
+			if f.Name == "xl/worksheets/sheet1.xml" {
+				rc, err := f.Open()
+				assert.NoError(t, err)
+				sheet, _ = io.ReadAll(rc)
+				rc.Close()
+			}
+		}
+		assert.Len(t, zr.File, 5)
+		assert.Contains(t, string(sheet), "<row><c t=\"inlineStr\"><is><t xml:space=\"preserve\">id</t></is></c>")
+		assert.Contains(t, string(sheet), "&amp; &lt;tag&gt;")
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := export.NewWriter("pdf", io.Discard)
+		assert.ErrorIs(t, err, export.ErrUnsupportedFormat)
+	})
+}
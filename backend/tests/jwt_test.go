@@ -228,3 +228,21 @@ func TestSessionManagement(t *testing.T) {
 		assert.Equal(t, jwt.ErrSessionNotFound, err)
 	})
 }
+
+func TestJWTEmailChangeToken(t *testing.T) {
+	jwt.Init(setupJWTConfig())
+
+	token, err := jwt.GenerateEmailChangeToken(1, "old@example.com", "new@example.com", jwt.EmailChangeConfirm, time.Hour)
+	assert.NoError(t, err)
+
+	claims, err := jwt.ValidateEmailChangeToken(token, jwt.EmailChangeConfirm)
+	assert.NoError(t, err)
+	assert.Equal(t, "old@example.com", claims.OldEmail)
+	assert.Equal(t, "new@example.com", claims.NewEmail)
+
+	// Onay token'ı geri alma veya access token olarak kullanılamaz
+	_, err = jwt.ValidateEmailChangeToken(token, jwt.EmailChangeRevert)
+	assert.Error(t, err)
+	_, err = jwt.Validate(token)
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"github.com/Furkanturan8/goftr-template/migrations"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(sql string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(sql)} }
+
+	t.Run("Embedded", func(t *testing.T) {
+		assert.NotEmpty(t, migrations.Migrations)
+		for i, m := range migrations.Migrations {
+			assert.NotEmpty(t, m.Up, m.Version)
+			assert.NotEmpty(t, m.Down, m.Version)
+			if i > 0 {
+				assert.Less(t, migrations.Migrations[i-1].Version, m.Version)
+			}
+		}
+	})
+
+	t.Run("Sıralı", func(t *testing.T) {
+		loaded, err := migrations.Load(fstest.MapFS{
+			"000002_add_phone.up.sql":      file("ALTER TABLE users ADD phone TEXT;"),
+			"000002_add_phone.down.sql":    file("ALTER TABLE users DROP phone;"),
+			"000001_create_users.up.sql":   file("CREATE TABLE users ();"),
+			"000001_create_users.down.sql": file("DROP TABLE users;"),
+		})
+		assert.NoError(t, err)
+		if assert.Len(t, loaded, 2) {
+			assert.Equal(t, "000001", loaded[0].Version)
+			assert.Equal(t, "create_users", loaded[0].Name)
+			assert.Equal(t, "DROP TABLE users;", loaded[0].Down)
+			assert.Equal(t, "000002", loaded[1].Version)
+		}
+	})
+
+	invalid := map[string]fstest.MapFS{
+		"boşluk": {
+			"000001_a.up.sql": file("SELECT 1;"), "000001_a.down.sql": file("SELECT 1;"),
+			"000003_c.up.sql": file("SELECT 1;"), "000003_c.down.sql": file("SELECT 1;"),
+		},
+		"aynı version": {
+			"000001_a.up.sql": file("SELECT 1;"), "000001_a.down.sql": file("SELECT 1;"),
+			"000001_b.up.sql": file("SELECT 1;"), "000001_b.down.sql": file("SELECT 1;"),
+		},
+		"down eksik": {
+			"000001_a.up.sql": file("SELECT 1;"),
+		},
+		"geçersiz ad": {
+			"1_a.up.sql": file("SELECT 1;"),
+		},
+	}
+	for name, fsys := range invalid {
+		_, err := migrations.Load(fsys)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/Furkanturan8/goftr-template/config"
+	"github.com/Furkanturan8/goftr-template/internal/model"
+	"github.com/Furkanturan8/goftr-template/pkg/password"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+func passwordConfig(algorithm string) *config.PasswordConfig {
+	cfg := password.DefaultConfig()
+	cfg.Algorithm = algorithm
+	cfg.Argon2Memory = 1024
+	cfg.Argon2Iterations = 1
+	cfg.Argon2Parallelism = 1
+	cfg.BcryptCost = bcrypt.MinCost
+	cfg.PBKDF2Iterations = 1000
+	return &cfg
+}
+
+func TestPasswordHashers(t *testing.T) {
+	t.Cleanup(func() { _ = password.Init(passwordConfig(password.AlgorithmArgon2id)) })
+
+	prefixes := map[string]string{
+		password.AlgorithmArgon2id: "$argon2id$v=19$m=1024,t=1,p=1$",
+		password.AlgorithmBcrypt:   "$2a$04$",
+		password.AlgorithmPBKDF2:   "$pbkdf2-sha256$i=1000,l=32$",
+	}
+	for algorithm, prefix := range prefixes {
+		t.Run(algorithm, func(t *testing.T) {
+			assert.NoError(t, password.Init(passwordConfig(algorithm)))
+
+			hash, err := password.Hash("secret")
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(hash, prefix), hash)
+			assert.NoError(t, password.Validate(hash))
+			assert.False(t, password.NeedsRehash(hash))
+
+			ok, err := password.Verify("secret", hash)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			ok, _ = password.Verify("wrong", hash)
+			assert.False(t, ok)
+		})
+	}
+
+	assert.ErrorIs(t, password.Init(passwordConfig("md5")), password.ErrUnknownAlgorithm)
+}
+
+func TestPasswordRehash(t *testing.T) {
+	t.Cleanup(func() { _ = password.Init(passwordConfig(password.AlgorithmArgon2id)) })
+
+	assert.NoError(t, password.Init(passwordConfig(password.AlgorithmBcrypt)))
+	user := model.User{}
+	assert.NoError(t, user.SetPassword("secret"))
+	legacy := user.Password
+
+	// Algoritma değişti: başarılı doğrulamada hash yenilenir
+	assert.NoError(t, password.Init(passwordConfig(password.AlgorithmArgon2id)))
+	assert.True(t, password.NeedsRehash(legacy))
+	assert.False(t, user.CheckPassword("wrong"))
+	assert.False(t, user.PasswordRehashed())
+	assert.True(t, user.CheckPassword("secret"))
+	assert.True(t, user.PasswordRehashed())
+	assert.True(t, strings.HasPrefix(user.Password, "$argon2id$"))
+
+	// Aynı algoritma, farklı parametreler
+	cfg := passwordConfig(password.AlgorithmArgon2id)
+	cfg.Argon2Iterations = 2
+	assert.NoError(t, password.Init(cfg))
+	assert.True(t, password.NeedsRehash(user.Password))
+}
+
+func TestPasswordLegacyHashes(t *testing.T) {
+	// passlib pbkdf2_sha256 formatı: iterasyon sayısı ve '.' kullanan base64
+	salt := []byte("0123456789abcdef")
+	key := pbkdf2.Key([]byte("secret"), salt, 1000, sha256.Size, sha256.New)
+	ab64 := func(b []byte) string {
+		return strings.ReplaceAll(base64.RawStdEncoding.EncodeToString(b), "+", ".")
+	}
+	passlib := fmt.Sprintf("$pbkdf2-sha256$1000$%s$%s", ab64(salt), ab64(key))
+
+	assert.NoError(t, password.Validate(passlib))
+	ok, err := password.Verify("secret", passlib)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, password.NeedsRehash(passlib))
+
+	// Başka sistemlerin bcrypt sürüm kimlikleri
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	ok, err = password.Verify("secret", "$2y$"+string(hash[4:]))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.ErrorIs(t, password.Validate("plaintext"), password.ErrInvalidHash)
+	assert.ErrorIs(t, password.Validate("$md5$abc"), password.ErrUnknownAlgorithm)
+	assert.ErrorIs(t, password.Validate("$argon2id$v=19$m=4194304,t=1,p=1$c2FsdA$a2V5"), password.ErrInvalidHash)
+	assert.ErrorIs(t, password.Validate("$argon2id$v=19$m=1024,t=4294967295,p=1$c2FsdA$a2V5"), password.ErrInvalidHash)
+	assert.ErrorIs(t, password.Validate("$argon2id$v=19$m=1024,t=1,p=255$c2FsdA$a2V5"), password.ErrInvalidHash)
+	assert.ErrorIs(t, password.Validate("$pbkdf2-sha256$i=2147483647,l=32$c2FsdA$"+strings.Repeat("A", 43)), password.ErrInvalidHash)
+	assert.ErrorIs(t, password.Validate("$pbkdf2-sha256$999999999$c2FsdA$"+strings.Repeat("A", 43)), password.ErrInvalidHash)
+
+	// Sınır dışı ayarlarla üretilen hash'ler doğrulanamayacağı için Init bunları kabul etmez
+	cfg := passwordConfig(password.AlgorithmPBKDF2)
+	cfg.PBKDF2Iterations = 100_000_000
+	assert.Error(t, password.Init(cfg))
+	cfg = passwordConfig(password.AlgorithmArgon2id)
+	cfg.Argon2Iterations = 100
+	assert.Error(t, password.Init(cfg))
+}
+
+func rules(violations []password.Violation) []string {
+	result := make([]string, len(violations))
+	for i, v := range violations {
+		result[i] = v.Rule
+	}
+	return result
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	cfg := passwordConfig(password.AlgorithmArgon2id)
+	t.Cleanup(func() { _ = password.Init(passwordConfig(password.AlgorithmArgon2id)) })
+
+	// Sızdırılmış şifre veri seti: <prefix>.txt dosyasında SUFFIX:COUNT satırları
+	sum := sha1.Sum([]byte("Password123"))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	cfg.BreachedDir = t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(cfg.BreachedDir, hash[:5]+".txt"),
+		[]byte("0018A45C4D1DEF81644B54AB7F969B88D65:3\r\n"+hash[5:]+":42\r\n"), 0o644))
+	cfg.RequireSymbol = true
+	assert.NoError(t, password.Init(cfg))
+
+	assert.Empty(t, password.CheckPolicy("Tr0ub4dor&3", "ali@example.com", "Ali", "Veli"))
+	assert.ElementsMatch(t, []string{password.RuleMinLength, password.RuleUppercase, password.RuleDigit, password.RuleSymbol},
+		rules(password.CheckPolicy("short")))
+	assert.Equal(t, []string{password.RulePersonalInfo}, rules(password.CheckPolicy("Xfurkan.99", "Furkan.Turan@example.com")))
+	assert.Equal(t, []string{password.RuleSymbol, password.RuleBreached}, rules(password.CheckPolicy("Password123")))
+
+	err := password.PolicyError(password.CheckPolicy("short"))
+	assert.Error(t, err)
+	assert.NoError(t, password.PolicyError(nil))
+}
+
+func TestPasswordHistoryAndAge(t *testing.T) {
+	cfg := passwordConfig(password.AlgorithmArgon2id)
+	cfg.HistorySize = 3
+	cfg.MaxAgeDays = 90
+	t.Cleanup(func() { _ = password.Init(passwordConfig(password.AlgorithmArgon2id)) })
+	assert.NoError(t, password.Init(cfg))
+
+	user := model.User{}
+	for _, p := range []string{"FirstPass1", "SecondPass2", "ThirdPass3", "FourthPass4"} {
+		assert.NoError(t, user.SetPassword(p))
+	}
+	assert.Len(t, user.PasswordHistory, 2, "güncel şifre ile birlikte 3 şifre tutulur")
+
+	assert.Equal(t, []string{password.RuleHistory}, rules(user.PasswordViolations("FourthPass4")))
+	assert.Equal(t, []string{password.RuleHistory}, rules(user.PasswordViolations("SecondPass2")))
+	assert.Empty(t, user.PasswordViolations("FirstPass1"), "geçmişten düşen şifre tekrar kullanılabilir")
+
+	assert.False(t, user.PasswordExpired())
+	old := time.Now().Add(-91 * 24 * time.Hour)
+	user.PasswordChangedAt = &old
+	assert.True(t, user.PasswordExpired())
+}
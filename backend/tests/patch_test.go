@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"github.com/Furkanturan8/goftr-template/internal/dto"
+	"github.com/Furkanturan8/goftr-template/internal/model"
+	"github.com/Furkanturan8/goftr-template/pkg/errorx"
+	"github.com/Furkanturan8/goftr-template/pkg/patch"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	testCases := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+	}{
+		{"Replace Field", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"Add Field", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"Remove Field", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"Nested Object", `{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{"Replace Array", `{"a":["b"]}`, `{"a":["c","d"]}`, `{"a":["c","d"]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := patch.MergePatch([]byte(tc.doc), []byte(tc.patch))
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(result))
+		})
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	doc := `{"name":"a","tags":["x","y"]}`
+
+	t.Run("Replace And Add", func(t *testing.T) {
+		result, err := patch.ApplyJSONPatch([]byte(doc), []byte(`[
+			{"op":"replace","path":"/name","value":"b"},
+			{"op":"add","path":"/tags/1","value":"z"},
+			{"op":"add","path":"/tags/-","value":"w"}
+		]`))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"name":"b","tags":["x","z","y","w"]}`, string(result))
+	})
+
+	t.Run("Remove Move Copy", func(t *testing.T) {
+		result, err := patch.ApplyJSONPatch([]byte(doc), []byte(`[
+			{"op":"remove","path":"/tags/0"},
+			{"op":"copy","from":"/name","path":"/alias"},
+			{"op":"move","from":"/name","path":"/title"}
+		]`))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"alias":"a","title":"a","tags":["y"]}`, string(result))
+	})
+
+	t.Run("Failed Test Operation", func(t *testing.T) {
+		_, err := patch.ApplyJSONPatch([]byte(doc), []byte(`[{"op":"test","path":"/name","value":"b"}]`))
+		assert.ErrorIs(t, err, patch.ErrTestFailed)
+	})
+
+	t.Run("Missing Path", func(t *testing.T) {
+		_, err := patch.ApplyJSONPatch([]byte(doc), []byte(`[{"op":"replace","path":"/missing","value":1}]`))
+		assert.ErrorIs(t, err, patch.ErrPathNotFound)
+	})
+}
+
+func TestApplyUserPatch(t *testing.T) {
+	current := model.User{
+		BaseModel: model.BaseModel{ID: 1},
+		Email:     "admin@example.com",
+		FirstName: "Admin",
+		LastName:  "User",
+		Role:      model.AdminRole,
+		Status:    model.StatusActive,
+	}
+
+	t.Run("Only Present Fields Change", func(t *testing.T) {
+		result, err := dto.ApplyUserPatch(current, patch.ContentTypeMergePatch, []byte(`{"first_name":"Yeni"}`), model.AdminRole)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"first_name"}, result.Columns)
+		assert.Equal(t, "Yeni", result.User.FirstName)
+		assert.Equal(t, model.AdminRole, result.User.Role)
+		assert.Equal(t, model.StatusActive, result.User.Status)
+	})
+
+	t.Run("JSON Patch", func(t *testing.T) {
+		result, err := dto.ApplyUserPatch(current, patch.ContentTypeJSONPatch, []byte(`[{"op":"replace","path":"/role","value":"user"}]`), model.AdminRole)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"role"}, result.Columns)
+		assert.Equal(t, model.UserRole, result.User.Role)
+	})
+
+	t.Run("Status Is Not Patchable", func(t *testing.T) {
+		_, err := dto.ApplyUserPatch(current, patch.ContentTypeJSONPatch, []byte(`[{"op":"replace","path":"/status","value":"banned"}]`), model.AdminRole)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*errorx.AppError).Code)
+
+		// Değişmeyen durum sorun değil
+		_, err = dto.ApplyUserPatch(current, patch.ContentTypeMergePatch, []byte(`{"status":"active","first_name":"Yeni"}`), model.AdminRole)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Role Change Requires Admin", func(t *testing.T) {
+		_, err := dto.ApplyUserPatch(current, patch.ContentTypeMergePatch, []byte(`{"role":"user"}`), model.UserRole)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, err.(*errorx.AppError).Code)
+	})
+
+	t.Run("Invalid Email", func(t *testing.T) {
+		_, err := dto.ApplyUserPatch(current, patch.ContentTypeMergePatch, []byte(`{"email":"not-an-email"}`), model.AdminRole)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*errorx.AppError).Code)
+	})
+
+	t.Run("Unknown Field", func(t *testing.T) {
+		_, err := dto.ApplyUserPatch(current, patch.ContentTypeMergePatch, []byte(`{"password":"x"}`), model.AdminRole)
+		assert.Error(t, err)
+	})
+
+	t.Run("Nullable Field Cleared", func(t *testing.T) {
+		result, err := dto.ApplyUserPatch(current, patch.ContentTypeMergePatch, []byte(`{"last_name":null}`), model.UserRole)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"last_name"}, result.Columns)
+		assert.Equal(t, "", result.User.LastName)
+	})
+}
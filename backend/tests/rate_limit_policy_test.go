@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"github.com/Furkanturan8/goftr-template/internal/middleware"
+	"github.com/Furkanturan8/goftr-template/pkg/cache"
+	"github.com/Furkanturan8/goftr-template/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseRatePolicy(t *testing.T) {
+	policy, err := middleware.ParseRatePolicy("api", "*=120/1m, admin=1000/1m")
+	assert.NoError(t, err)
+
+	limit, ok := policy.LimitFor("admin")
+	assert.True(t, ok)
+	assert.Equal(t, cache.RateLimit{Limit: 1000, Period: time.Minute}, limit)
+
+	limit, ok = policy.LimitFor(middleware.AnonymousPlan)
+	assert.True(t, ok)
+	assert.Equal(t, 120, limit.Limit)
+
+	// "*" yoksa listelenmeyen planlar sınırlanmaz
+	policy, err = middleware.ParseRatePolicy("auth", "anonymous=5/1m")
+	assert.NoError(t, err)
+	_, ok = policy.LimitFor("user")
+	assert.False(t, ok)
+
+	for _, spec := range []string{"*=120", "*=0/1m", "*=x/1m", "*=10/abc", "120/1m"} {
+		_, err := middleware.ParseRatePolicy("api", spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	limiter, err := middleware.NewRateLimiter(cache.NewMemoryCache(0), "partner-key=partner")
+	assert.NoError(t, err)
+	policy, err := middleware.ParseRatePolicy("api", "*=2/1m,partner=3/1m")
+	assert.NoError(t, err)
+
+	app := fiber.New(fiber.Config{ErrorHandler: response.ErrorHandler})
+	app.Use(limiter.Limit(policy))
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	do := func(apiKey string) *http.Response {
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	resp := do("")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("RateLimit-Limit"))
+	assert.Equal(t, "1", resp.Header.Get("RateLimit-Remaining"))
+	assert.Equal(t, "2;w=60", resp.Header.Get("RateLimit-Policy"))
+
+	assert.Equal(t, http.StatusOK, do("").StatusCode)
+	resp = do("")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "0", resp.Header.Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", resp.Header.Get("Retry-After"))
+
+	// API key'in kendi planı ve sayacı vardır; tanımsız key anonim sayılır
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, do("partner-key").StatusCode)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, do("partner-key").StatusCode)
+	assert.Equal(t, http.StatusTooManyRequests, do("unknown-key").StatusCode)
+}
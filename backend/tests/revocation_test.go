@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"context"
+	"github.com/Furkanturan8/goftr-template/internal/middleware"
+	"github.com/Furkanturan8/goftr-template/pkg/cache"
+	"github.com/Furkanturan8/goftr-template/pkg/jwt"
+	"github.com/Furkanturan8/goftr-template/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTokenRevocation(t *testing.T) {
+	jwt.Init(setupJWTConfig())
+	cache.SetDefault(cache.NewMemoryCache(0))
+	defer cache.SetDefault(nil)
+	ctx := context.Background()
+
+	user := setupTestUser()
+	user.ID = 42
+
+	t.Run("Not Revoked", func(t *testing.T) {
+		token, err := jwt.Generate(user)
+		assert.NoError(t, err)
+		claims, err := jwt.Validate(token)
+		assert.NoError(t, err)
+		assert.NoError(t, jwt.CheckRevoked(ctx, claims))
+	})
+
+	t.Run("Revoked Before And Issued After", func(t *testing.T) {
+		before, _ := jwt.Generate(user)
+		time.Sleep(2 * time.Millisecond)
+		assert.NoError(t, jwt.RevokeUserTokens(ctx, user.ID))
+		time.Sleep(2 * time.Millisecond)
+		// İptalden hemen sonra (büyük ihtimalle aynı saniyede) alınan token geçerli olmalı
+		after, _ := jwt.Generate(user)
+
+		claims, err := jwt.Validate(before)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, jwt.CheckRevoked(ctx, claims), jwt.ErrTokenRevoked)
+
+		claims, err = jwt.Validate(after)
+		assert.NoError(t, err)
+		assert.NoError(t, jwt.CheckRevoked(ctx, claims))
+	})
+
+	t.Run("Token Without iat_ms", func(t *testing.T) {
+		revokedAt := time.Now()
+		assert.NoError(t, cache.Set(ctx, "token_valid_after:7", revokedAt.UnixMilli(), time.Minute))
+
+		// iat_ms taşımayan token'larda saniyeye yuvarlanmış iat kullanılır
+		before := &jwt.Claims{UserID: 7}
+		before.IssuedAt = gojwt.NewNumericDate(revokedAt.Add(-time.Second))
+		assert.ErrorIs(t, jwt.CheckRevoked(ctx, before), jwt.ErrTokenRevoked)
+
+		after := &jwt.Claims{UserID: 7}
+		after.IssuedAt = gojwt.NewNumericDate(revokedAt.Add(2 * time.Second))
+		assert.NoError(t, jwt.CheckRevoked(ctx, after))
+	})
+
+	t.Run("Middleware Rejects Revoked Token", func(t *testing.T) {
+		app := fiber.New(fiber.Config{ErrorHandler: response.ErrorHandler})
+		app.Get("/", middleware.AuthMiddleware(), func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+		do := func(token string) int {
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			return resp.StatusCode
+		}
+
+		old, _ := jwt.Generate(user)
+		assert.Equal(t, http.StatusOK, do(old))
+
+		time.Sleep(2 * time.Millisecond)
+		assert.NoError(t, jwt.RevokeUserTokens(ctx, user.ID))
+		assert.Equal(t, http.StatusUnauthorized, do(old))
+
+		time.Sleep(2 * time.Millisecond)
+		fresh, _ := jwt.Generate(user)
+		assert.Equal(t, http.StatusOK, do(fresh))
+	})
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"context"
+	"github.com/Furkanturan8/goftr-template/pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// localBus Redis pub/sub yerine mesajları aynı process'teki abonelere iletir
+type localBus struct {
+	mu        sync.Mutex
+	handlers  []func([]byte)
+	published int
+}
+
+func (b *localBus) Publish(_ context.Context, msg []byte) error {
+	b.mu.Lock()
+	b.published++
+	handlers := append([]func([]byte){}, b.handlers...)
+	b.mu.Unlock()
+	for _, h := range handlers {
+		h(msg)
+	}
+	return nil
+}
+
+func (b *localBus) Subscribe(handler func([]byte)) func() {
+	b.mu.Lock()
+	b.handlers = append(b.handlers, handler)
+	b.mu.Unlock()
+	return func() {}
+}
+
+func (b *localBus) count() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.published
+}
+
+func TestTieredCache(t *testing.T) {
+	ctx := context.Background()
+	l2 := cache.NewMemoryCache(100)
+	bus := &localBus{}
+	a, err := cache.NewTieredCache(cache.NewMemoryCache(100), l2, bus, time.Minute)
+	assert.NoError(t, err)
+	b, err := cache.NewTieredCache(cache.NewMemoryCache(100), l2, bus, time.Minute)
+	assert.NoError(t, err)
+
+	t.Run("Hit Miss", func(t *testing.T) {
+		var v int
+		assert.ErrorIs(t, b.Get(ctx, "k", &v), cache.ErrNotFound)
+
+		// Olmayan key'in yazılması duyurulmaz
+		assert.NoError(t, a.Set(ctx, "k", 1, 0))
+		assert.Equal(t, 0, bus.count())
+
+		assert.NoError(t, b.Get(ctx, "k", &v)) // L2'den okunur, L1'e yazılır
+		assert.NoError(t, b.Get(ctx, "k", &v))
+		assert.Equal(t, 1, v)
+		assert.Equal(t, cache.TierStats{L1Hits: 1, L1Misses: 2, L2Hits: 1, L2Misses: 1}, b.Stats())
+	})
+
+	t.Run("Remote Invalidation", func(t *testing.T) {
+		var v int
+		assert.NoError(t, a.Set(ctx, "k", 2, 0))
+		assert.NoError(t, b.Get(ctx, "k", &v))
+		assert.Equal(t, 2, v, "üzerine yazma diğer instance'ın L1'ini siler")
+
+		assert.NoError(t, a.Delete(ctx, "k"))
+		assert.ErrorIs(t, b.Get(ctx, "k", &v), cache.ErrNotFound)
+
+		assert.NoError(t, a.SetWithTags(ctx, "user:1", 1, 0, "users"))
+		assert.NoError(t, b.Get(ctx, "user:1", &v))
+		assert.NoError(t, a.InvalidateTags(ctx, "users"))
+		assert.ErrorIs(t, b.Get(ctx, "user:1", &v), cache.ErrNotFound)
+	})
+
+	t.Run("L1 Outlives L2", func(t *testing.T) {
+		var v int
+		assert.NoError(t, l2.Set(ctx, "short", 1, 30*time.Millisecond))
+		assert.NoError(t, b.Get(ctx, "short", &v))
+
+		time.Sleep(40 * time.Millisecond)
+		assert.ErrorIs(t, b.Get(ctx, "short", &v), cache.ErrNotFound, "L1 kopyası L2'deki süreyi aşmamalı")
+	})
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"github.com/Furkanturan8/goftr-template/internal/dto"
+	"github.com/Furkanturan8/goftr-template/internal/model"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestParseUserImport(t *testing.T) {
+	t.Run("CSV", func(t *testing.T) {
+		csv := "email,first_name,last_name,password,role\n" +
+			"Ali@Example.com,Ali,Veli,Secret123,admin\n" +
+			"invalid,Ayşe,,x,\n"
+
+		rows, err := dto.ParseUserImport(dto.ImportFormatCSV, strings.NewReader(csv))
+		assert.NoError(t, err)
+		assert.Len(t, rows, 2)
+		assert.Equal(t, 2, rows[0].Line)
+		assert.Equal(t, 3, rows[1].Line)
+
+		user, errs := rows[0].Prepare(false)
+		assert.Empty(t, errs)
+		assert.Equal(t, "ali@example.com", user.Email)
+		assert.Equal(t, model.AdminRole, user.Role)
+		assert.Equal(t, model.StatusActive, user.Status)
+		assert.True(t, user.CheckPassword("Secret123"))
+
+		_, errs = rows[1].Prepare(false)
+		assert.NotEmpty(t, errs)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		rows, err := dto.ParseUserImport(dto.ImportFormatJSON, strings.NewReader(`[{"email":"a@b.com","first_name":"A","last_name":"B"}]`))
+		assert.NoError(t, err)
+		assert.Len(t, rows, 1)
+
+		_, errs := rows[0].Prepare(false)
+		assert.NotEmpty(t, errs, "şifre zorunlu")
+
+		user, errs := rows[0].Prepare(true)
+		assert.Empty(t, errs, "davet modunda şifre üretilir")
+		assert.NotEmpty(t, user.Password)
+	})
+
+	t.Run("Password Hash", func(t *testing.T) {
+		hash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+		csv := "email,first_name,last_name,password,password_hash\n" +
+			"a@b.com,A,B,," + string(hash) + "\n" +
+			"c@d.com,C,D,secret," + string(hash) + "\n" +
+			"e@f.com,E,F,,not-a-hash\n"
+
+		rows, err := dto.ParseUserImport(dto.ImportFormatCSV, strings.NewReader(csv))
+		assert.NoError(t, err)
+
+		user, errs := rows[0].Prepare(false)
+		assert.Empty(t, errs)
+		assert.Equal(t, string(hash), user.Password)
+		assert.True(t, user.CheckPassword("secret"))
+
+		_, errs = rows[1].Prepare(false)
+		assert.NotEmpty(t, errs, "şifre ve hash birlikte verilemez")
+		_, errs = rows[2].Prepare(true)
+		assert.NotEmpty(t, errs, "geçersiz hash")
+	})
+
+	t.Run("Missing Email Column", func(t *testing.T) {
+		_, err := dto.ParseUserImport(dto.ImportFormatCSV, strings.NewReader("first_name\nAli\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Unknown Format", func(t *testing.T) {
+		_, err := dto.ParseUserImport("xml", strings.NewReader(""))
+		assert.Error(t, err)
+	})
+}
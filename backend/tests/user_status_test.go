@@ -0,0 +1,22 @@
+package tests
+
+import (
+	"github.com/Furkanturan8/goftr-template/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUserStatusTransitions(t *testing.T) {
+	assert.True(t, model.StatusActive.CanTransitionTo(model.StatusSuspended))
+	assert.True(t, model.StatusSuspended.CanTransitionTo(model.StatusBanned))
+	assert.True(t, model.StatusBanned.CanTransitionTo(model.StatusActive))
+	assert.False(t, model.StatusBanned.CanTransitionTo(model.StatusSuspended))
+	assert.False(t, model.StatusActive.CanTransitionTo(model.StatusActive))
+
+	assert.True(t, model.StatusBanned.Restrictive())
+	assert.True(t, model.StatusSuspended.Restrictive())
+	assert.False(t, model.StatusInactive.Restrictive())
+
+	assert.True(t, model.StatusSuspended.Valid())
+	assert.False(t, model.Status("deleted").Valid())
+}
@@ -0,0 +1,20 @@
+package tests
+
+import (
+	"github.com/Furkanturan8/goftr-template/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidateUsername(t *testing.T) {
+	assert.NoError(t, model.ValidateUsername("furkan"))
+	assert.NoError(t, model.ValidateUsername("Furkan.Turan_8"))
+
+	assert.ErrorIs(t, model.ValidateUsername("ab"), model.ErrUsernameLength)
+	assert.ErrorIs(t, model.ValidateUsername("a234567890123456789012345678901"), model.ErrUsernameLength)
+	assert.ErrorIs(t, model.ValidateUsername("8furkan"), model.ErrUsernameFormat)
+	assert.ErrorIs(t, model.ValidateUsername("furkan_"), model.ErrUsernameFormat)
+	assert.ErrorIs(t, model.ValidateUsername("furkan@mail"), model.ErrUsernameFormat)
+	assert.ErrorIs(t, model.ValidateUsername("fur..kan"), model.ErrUsernameRepeat)
+	assert.ErrorIs(t, model.ValidateUsername("Admin"), model.ErrUsernameReserved)
+}
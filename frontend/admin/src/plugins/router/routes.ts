@@ -1,5 +1,19 @@
 import {NavigationGuardNext, RouteLocationNormalized} from "vue-router";
 
+// Linkle açılan sayfalar (şifre sıfırlama, e-posta onayı) token olmadan açılamaz
+const requireToken = (
+  to: RouteLocationNormalized,
+  from: RouteLocationNormalized,
+  next: NavigationGuardNext
+) => {
+  const token = to.query.token
+  if (!token) {
+    next('/login')
+  } else {
+    next()
+  }
+}
+
 export const routes = [
   { path: '/', redirect: '/dashboard' },
   {
@@ -35,18 +49,17 @@ export const routes = [
       {
         path: 'reset-password',
         component: () => import('@/pages/reset-password.vue'),
-        beforeEnter: (
-          to: RouteLocationNormalized,
-          from: RouteLocationNormalized,
-          next: NavigationGuardNext
-        ) => {
-          const token = to.query.token
-          if (!token) {
-            next('/login')
-          } else {
-            next()
-          }
-        },
+        beforeEnter: requireToken,
+      },
+      {
+        path: 'confirm-email',
+        component: () => import('@/pages/email-change.vue'),
+        beforeEnter: requireToken,
+      },
+      {
+        path: 'revert-email',
+        component: () => import('@/pages/email-change.vue'),
+        beforeEnter: requireToken,
       },
       {
         path: '/:pathMatch(.*)*',
@@ -75,9 +75,12 @@ ApiService.interceptors.response.use(
 export const authService = {
   register: (data: any) => ApiService.post('/auth/register', data),
   login: (data: any) => ApiService.post('/auth/login', data),
+  verifyTwoFactor: (data: { challenge_token: string; code: string }) => ApiService.post('/auth/login/2fa', data),
   logout: () => ApiService.post('/auth/logout'),
   forgotPassword: (data: any) => ApiService.post('/auth/forgot-password', data),
   resetPassword: (data: any) => ApiService.post('/auth/reset-password', data),
+  confirmEmail: (token: string) => ApiService.post('/auth/confirm-email', { token }),
+  revertEmail: (token: string) => ApiService.post('/auth/revert-email', { token }),
 }
 
 // Kullanıcı servisleri
@@ -86,11 +89,16 @@ export const userService = {
   updateProfile: (data: any) => ApiService.put('/users/me', data),
 
   // Admin only routes
-  listUsers: () => ApiService.get('/users'),
+  listUsers: (params: any = { page_size: 100 }) => ApiService.get('/users', { params }),
   createUser: (data: any) => ApiService.post('/users', data),
   getUserById: (id: string) => ApiService.get(`/users/${id}`),
-  updateUser: (id: number, data: any) => ApiService.put(`/users/${id}`, data),
-  deleteUser: (id: number) => ApiService.delete(`/users/${id}`),
+  updateUser: (id: number, data: any, version: number) =>
+    ApiService.put(`/users/${id}`, data, { headers: { 'If-Match': `"${version}"` } }),
+  deleteUser: (id: number, version: number) =>
+    ApiService.delete(`/users/${id}`, { headers: { 'If-Match': `"${version}"` } }),
+  changeUserStatus: (id: number, data: { status: string; reason?: string; expires_at?: string }, version: number) =>
+    ApiService.post(`/users/${id}/status`, data, { headers: { 'If-Match': `"${version}"` } }),
+  getUserStatusHistory: (id: number) => ApiService.get(`/users/${id}/status-history`),
 }
 
 export { ApiService }
@@ -13,3 +13,14 @@ export function passwordAgainVAL(newPassword: string) {
     (v: string) => v === newPassword || 'Şifreler uyuşmuyor',
   ]
 }
+
+// API hata yanıtındaki mesajı döner; şifre politikası gibi kural bazlı
+// detaylar (errors: [{rule, message}]) varsa onları satır satır ekler
+export function apiErrorMessage(err: any, fallback: string): string {
+  const data = err?.response?.data
+  const details = Array.isArray(data?.errors) ? data.errors.map((e: any) => e.message).filter(Boolean) : []
+  if (details.length) {
+    return details.join('\n')
+  }
+  return data?.message || fallback
+}